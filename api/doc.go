@@ -0,0 +1,10 @@
+/*
+Package api provides an HTTP API for thrall's workers.
+
+It does expose an http.Handler, to be mounted on any http server, that accepts
+job submissions by their registered type name with a JSON payload, and serves
+the jobs statuses, the pool stats and queue depth, the running and scheduled
+jobs, the recent failures and the dead letters. It does also cancel jobs, retry
+dead letters and pause or resume the pool, as the dashboard package does.
+*/
+package api
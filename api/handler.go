@@ -0,0 +1,275 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/jcleira/thrall"
+)
+
+// Submission is a job submission request, the job is decoded from it's
+// registered type name and JSON payload.
+type Submission struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Submitted is a job submission response.
+type Submitted struct {
+	ID string `json:"id"`
+}
+
+// errorResponse is the body of every error response.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler is thrall's HTTP API http.Handler, it serves the following routes:
+//
+//   - POST /jobs: Submits a job, the body is a Submission.
+//   - GET /jobs/{id}: Returns a job's status.
+//   - POST /jobs/{id}/cancel: Cancels a job.
+//   - GET /stats: Returns the pool stats, queue depth and limiter utilization.
+//   - GET /running: Returns the running jobs statuses.
+//   - GET /scheduled: Returns the scheduled jobs statuses.
+//   - GET /failures: Returns the jobs which last run failed.
+//   - GET /dead-letters: Returns the failed jobs that won't be retried.
+//   - POST /dead-letters/{id}/retry: Submits a dead letter's job again.
+//   - DELETE /dead-letters/{id}: Removes a dead letter.
+//   - POST /pause, POST /resume: Pauses or resumes the pool, or a single job
+//     type given as the type query param.
+//
+// Mount it with http.StripPrefix to serve it under a path.
+type Handler struct {
+	// Codec decodes the submitted jobs.
+	Codec thrall.Codec
+
+	mux *http.ServeMux
+}
+
+// NewHandler creates thrall's HTTP API Handler.
+//
+// - types: The registry of the job types that can be submitted.
+//
+// Returns the Handler.
+func NewHandler(types *thrall.TypeRegistry) *Handler {
+	h := &Handler{
+		Codec: thrall.NewJSONCodec(types),
+		mux:   http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("/jobs", h.method(http.MethodPost, h.submit))
+	h.mux.HandleFunc("/jobs/", h.job)
+	h.mux.HandleFunc("/stats", h.method(http.MethodGet, h.stats))
+	h.mux.HandleFunc("/running", h.method(http.MethodGet, h.running))
+	h.mux.HandleFunc("/scheduled", h.method(http.MethodGet, h.scheduled))
+	h.mux.HandleFunc("/failures", h.method(http.MethodGet, h.failures))
+	h.mux.HandleFunc("/dead-letters", h.method(http.MethodGet, h.deadLetters))
+	h.mux.HandleFunc("/dead-letters/", h.deadLetter)
+	h.mux.HandleFunc("/pause", h.method(http.MethodPost, h.pause))
+	h.mux.HandleFunc("/resume", h.method(http.MethodPost, h.resume))
+
+	return h
+}
+
+// ServeHTTP serves thrall's HTTP API.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// submit decodes and submits a job.
+func (h *Handler) submit(w http.ResponseWriter, r *http.Request) {
+	var submission Submission
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid submission. Err: "+err.Error())
+		return
+	}
+
+	payload := []byte(submission.Payload)
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	job, err := h.Codec.Decode(submission.Type, payload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch id, err := thrall.Submit(job); err {
+	case nil:
+		writeJSON(w, http.StatusAccepted, Submitted{ID: id})
+	case thrall.ErrDuplicateJob:
+		writeError(w, http.StatusConflict, err.Error())
+	case thrall.ErrQueueFull:
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	}
+}
+
+// job routes the requests on a single job.
+func (h *Handler) job(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/cancel") {
+		h.method(http.MethodPost, h.cancel)(w, r)
+		return
+	}
+
+	h.method(http.MethodGet, h.status)(w, r)
+}
+
+// status returns a job's status.
+func (h *Handler) status(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+
+	status, exists := thrall.Status(id)
+	if !exists {
+		writeError(w, http.StatusNotFound, "job '"+id+"' not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// cancel cancels a job.
+func (h *Handler) cancel(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/cancel")
+
+	switch err := thrall.Cancel(id); err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case thrall.ErrJobNotFound:
+		writeError(w, http.StatusNotFound, "job '"+id+"' not found")
+	default:
+		writeError(w, http.StatusConflict, err.Error())
+	}
+}
+
+// stats returns the pool stats.
+func (h *Handler) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, thrall.Stats())
+}
+
+// running returns the running jobs statuses.
+func (h *Handler) running(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, thrall.Running())
+}
+
+// scheduled returns the scheduled jobs statuses.
+func (h *Handler) scheduled(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, thrall.Scheduled())
+}
+
+// failures returns the jobs which last run failed.
+func (h *Handler) failures(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, thrall.Failures())
+}
+
+// deadLetters returns the dead letters.
+func (h *Handler) deadLetters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, thrall.DeadLetters())
+}
+
+// deadLetter routes the requests on a single dead letter.
+func (h *Handler) deadLetter(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/retry") {
+		h.method(http.MethodPost, h.retry)(w, r)
+		return
+	}
+
+	h.method(http.MethodDelete, h.purge)(w, r)
+}
+
+// retry submits a dead letter's job again.
+func (h *Handler) retry(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/dead-letters/"), "/retry")
+
+	retryID, err := thrall.Retry(id)
+	switch err {
+	case nil:
+		writeJSON(w, http.StatusAccepted, Submitted{ID: retryID})
+	case thrall.ErrJobNotFound:
+		writeError(w, http.StatusNotFound, "dead letter '"+id+"' not found")
+	case thrall.ErrQueueFull:
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	}
+}
+
+// purge removes a dead letter.
+func (h *Handler) purge(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/dead-letters/")
+
+	if err := thrall.Purge(id); err != nil {
+		writeError(w, http.StatusNotFound, "dead letter '"+id+"' not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pause pauses the pool, or a single job type.
+func (h *Handler) pause(w http.ResponseWriter, r *http.Request) {
+	if t := r.URL.Query().Get("type"); t != "" {
+		thrall.PauseJobType(t)
+	} else {
+		thrall.Pause()
+	}
+
+	writeJSON(w, http.StatusOK, thrall.Stats())
+}
+
+// resume resumes the pool, or a single job type.
+func (h *Handler) resume(w http.ResponseWriter, r *http.Request) {
+	if t := r.URL.Query().Get("type"); t != "" {
+		thrall.ResumeJobType(t)
+	} else {
+		thrall.Resume()
+	}
+
+	writeJSON(w, http.StatusOK, thrall.Stats())
+}
+
+// method restricts a route to a single HTTP method.
+//
+// - method: The allowed HTTP method.
+// - handler: The route handler.
+//
+// Returns the restricted route handler.
+func (h *Handler) method(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// writeJSON writes a JSON response.
+//
+// - w: The response writer.
+// - code: The response status code.
+// - body: The response body.
+//
+// Returns nothing.
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes a JSON error response.
+//
+// - w: The response writer.
+// - code: The response status code.
+// - message: The error message.
+//
+// Returns nothing.
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, errorResponse{Error: message})
+}
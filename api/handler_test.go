@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"github.com/stretchr/testify/assert"
+)
+
+type greetJob struct {
+	Who  string `json:"who"`
+	Fail bool   `json:"fail"`
+}
+
+func (gj *greetJob) Run() error {
+	if gj.Fail {
+		return errors.New("greet failed")
+	}
+
+	return nil
+}
+
+func (gj *greetJob) Name() string {
+	return "greet"
+}
+
+type laterJob struct{}
+
+func (lj *laterJob) Run() error {
+	return nil
+}
+
+func (lj *laterJob) Schedule() time.Time {
+	return time.Now().Add(time.Hour)
+}
+
+func newTestHandler() *Handler {
+	types := thrall.NewTypeRegistry()
+	types.Register("greet", func() thrall.Runnable { return &greetJob{} })
+	types.Register("later", func() thrall.Runnable { return &laterJob{} })
+
+	return NewHandler(types)
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	return recorder
+}
+
+func submit(t *testing.T, h http.Handler, body string) string {
+	recorder := serve(h, http.MethodPost, "/jobs", body)
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("job not submitted: %s", recorder.Body.String())
+	}
+
+	var submitted Submitted
+	json.Unmarshal(recorder.Body.Bytes(), &submitted)
+
+	return submitted.ID
+}
+
+func TestHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, close := thrall.Init(1, thrall.WithErrorsBuffer(10))
+	defer func() { close <- true }()
+
+	h := newTestHandler()
+
+	t.Run("when submit succeed running a job", func(t *testing.T) {
+		id := submit(t, h, `{"type":"greet","payload":{"who":"foo"}}`)
+		assert.Len(id, 32)
+
+		time.Sleep(20 * time.Millisecond)
+
+		recorder := serve(h, http.MethodGet, "/jobs/"+id, "")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var status thrall.JobStatus
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &status))
+		assert.Equal(id, status.ID)
+		assert.Equal("greet", status.Type)
+		assert.Equal(thrall.StateSucceeded, status.State)
+		assert.Equal(1, status.Attempts)
+	})
+
+	t.Run("when a failed job succeed being served as a dead letter", func(t *testing.T) {
+		id := submit(t, h, `{"type":"greet","payload":{"fail":true}}`)
+
+		time.Sleep(20 * time.Millisecond)
+
+		recorder := serve(h, http.MethodGet, "/dead-letters", "")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var dead []thrall.JobStatus
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &dead))
+		assert.Len(dead, 1)
+		assert.Equal(id, dead[0].ID)
+		assert.Equal(thrall.StateFailed, dead[0].State)
+		assert.Contains(dead[0].Error, "greet failed")
+	})
+
+	t.Run("when a scheduled job succeed being served", func(t *testing.T) {
+		id := submit(t, h, `{"type":"later"}`)
+
+		time.Sleep(10 * time.Millisecond)
+
+		recorder := serve(h, http.MethodGet, "/scheduled", "")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var scheduled []thrall.JobStatus
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &scheduled))
+		assert.Len(scheduled, 1)
+		assert.Equal(id, scheduled[0].ID)
+		assert.Equal(thrall.StateScheduled, scheduled[0].State)
+
+		recorder = serve(h, http.MethodGet, "/stats", "")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var stats thrall.PoolStats
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &stats))
+		assert.Equal(1, stats.Workers)
+		assert.Equal(1, stats.Scheduled)
+	})
+
+	t.Run("when cancel succeed canceling a scheduled job", func(t *testing.T) {
+		id := submit(t, h, `{"type":"later"}`)
+
+		time.Sleep(10 * time.Millisecond)
+
+		recorder := serve(h, http.MethodPost, "/jobs/"+id+"/cancel", "")
+		assert.Equal(http.StatusNoContent, recorder.Code)
+
+		status, _ := thrall.Status(id)
+		assert.Equal(thrall.StateCanceled, status.State)
+
+		recorder = serve(h, http.MethodPost, "/jobs/"+id+"/cancel", "")
+		assert.Equal(http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("when retry succeed submitting a dead letter again", func(t *testing.T) {
+		recorder := serve(h, http.MethodGet, "/failures", "")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var failures []thrall.JobStatus
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &failures))
+		assert.Len(failures, 1)
+
+		recorder = serve(h, http.MethodPost, "/dead-letters/"+failures[0].ID+"/retry", "")
+		assert.Equal(http.StatusAccepted, recorder.Code)
+
+		var submitted Submitted
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &submitted))
+		assert.NotEqual(failures[0].ID, submitted.ID)
+
+		recorder = serve(h, http.MethodPost, "/dead-letters/"+failures[0].ID+"/retry", "")
+		assert.Equal(http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("when purge succeed removing a dead letter", func(t *testing.T) {
+		time.Sleep(20 * time.Millisecond)
+
+		dead := thrall.DeadLetters()
+		assert.Len(dead, 1)
+
+		recorder := serve(h, http.MethodDelete, "/dead-letters/"+dead[0].ID, "")
+		assert.Equal(http.StatusNoContent, recorder.Code)
+		assert.Empty(thrall.DeadLetters())
+
+		recorder = serve(h, http.MethodDelete, "/dead-letters/"+dead[0].ID, "")
+		assert.Equal(http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("when pause succeed pausing the pool and a job type", func(t *testing.T) {
+		recorder := serve(h, http.MethodPost, "/pause?type=greet", "")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var stats thrall.PoolStats
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &stats))
+		assert.False(stats.Paused)
+		assert.Equal([]string{"greet"}, stats.PausedTypes)
+
+		recorder = serve(h, http.MethodPost, "/pause", "")
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &stats))
+		assert.True(stats.Paused)
+
+		serve(h, http.MethodPost, "/resume", "")
+		recorder = serve(h, http.MethodPost, "/resume?type=greet", "")
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &stats))
+		assert.False(stats.Paused)
+		assert.Empty(stats.PausedTypes)
+	})
+
+	t.Run("when submit fails", func(t *testing.T) {
+		t.Run("due an invalid body", func(t *testing.T) {
+			recorder := serve(h, http.MethodPost, "/jobs", `{`)
+			assert.Equal(http.StatusBadRequest, recorder.Code)
+		})
+
+		t.Run("due a not registered job type", func(t *testing.T) {
+			recorder := serve(h, http.MethodPost, "/jobs", `{"type":"foo"}`)
+			assert.Equal(http.StatusBadRequest, recorder.Code)
+			assert.Contains(recorder.Body.String(), "job type 'foo' not registered")
+		})
+
+		t.Run("due a not allowed method", func(t *testing.T) {
+			recorder := serve(h, http.MethodGet, "/jobs", "")
+			assert.Equal(http.StatusMethodNotAllowed, recorder.Code)
+			assert.Equal(http.MethodPost, recorder.Header().Get("Allow"))
+		})
+	})
+
+	t.Run("when status fails due an unknown job", func(t *testing.T) {
+		recorder := serve(h, http.MethodGet, "/jobs/foo", "")
+		assert.Equal(http.StatusNotFound, recorder.Code)
+	})
+}
@@ -0,0 +1,177 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// The Cancel errors.
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job already finished")
+)
+
+// Cancel cancels a job. The scheduled and held jobs are dropped right away,
+// the queued ones are dropped once they reach a worker, and the running ones
+// get their context canceled, so only the Contextual jobs that watch their
+// context would stop.
+//
+// - id: The job ID.
+//
+// Returns ErrJobNotFound if the job is unknown or ErrJobFinished if the job
+// is already finished.
+func Cancel(id string) error {
+	return wp.cancel(id)
+}
+
+// cancel cancels a job.
+//
+// - id: The job ID.
+//
+// Returns an error if the job is unknown or already finished.
+func (wp *workerPool) cancel(id string) error {
+	status, exists := wp.status(id)
+	if !exists {
+		return ErrJobNotFound
+	}
+
+	if status.State.Finished() {
+		return ErrJobFinished
+	}
+
+	wp.statusMutex.Lock()
+	wp.canceled[id] = true
+	cancelRun := wp.cancels[id]
+	wp.statusMutex.Unlock()
+
+	if cancelRun != nil {
+		cancelRun()
+		return nil
+	}
+
+	if e := wp.unschedule(id); e != nil {
+		wp.DecMetric(e.job, "workerpool_job_scheduled")
+		wp.dropCanceled(e)
+		return nil
+	}
+
+	if e := wp.unhold(id); e != nil {
+		wp.DecMetric(e.job, "workerpool_job_held")
+		wp.dropCanceled(e)
+	}
+
+	return nil
+}
+
+// unschedule removes a job from the scheduler.
+//
+// - id: The job ID.
+//
+// Returns the removed enveloped job, nil if it's not scheduled.
+func (wp *workerPool) unschedule(id string) *envelope {
+	wp.DelayedMutext.Lock()
+	defer wp.DelayedMutext.Unlock()
+
+	for schedule, envelopes := range wp.Delayed {
+		for i, e := range envelopes {
+			if e.id != id {
+				continue
+			}
+
+			envelopes = append(envelopes[:i:i], envelopes[i+1:]...)
+			if len(envelopes) == 0 {
+				delete(wp.Delayed, schedule)
+			} else {
+				wp.Delayed[schedule] = envelopes
+			}
+
+			return e
+		}
+	}
+
+	return nil
+}
+
+// unhold removes a job from the held paused jobs.
+//
+// - id: The job ID.
+//
+// Returns the removed enveloped job, nil if it's not held.
+func (wp *workerPool) unhold(id string) *envelope {
+	wp.pauseMutex.Lock()
+	defer wp.pauseMutex.Unlock()
+
+	for i, e := range wp.held {
+		if e.id == id {
+			wp.held = append(wp.held[:i:i], wp.held[i+1:]...)
+			return e
+		}
+	}
+
+	return nil
+}
+
+// skipCanceled drops a job that has been canceled while queued, it's called
+// right before running the job.
+//
+// - e: The enveloped job.
+//
+// Returns true if the job has been canceled.
+func (wp *workerPool) skipCanceled(e *envelope) bool {
+	wp.statusMutex.Lock()
+	canceled := wp.canceled[e.id]
+	wp.statusMutex.Unlock()
+
+	if !canceled {
+		return false
+	}
+
+	wp.dropCanceled(e)
+
+	return true
+}
+
+// dropCanceled drops a canceled job that didn't run, the stored jobs are
+// acked so they are not run again.
+//
+// - e: The canceled enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) dropCanceled(e *envelope) {
+	wp.endPhase(e)
+	wp.Logger.Debug("job canceled", jobFields(e)...)
+
+	if e.stored {
+		wp.ack(e)
+	}
+
+	wp.updateStatus(e, func(status *JobStatus) {
+		status.State = StateCanceled
+		status.Finished = time.Now()
+	})
+}
+
+// runContext returns a cancelable context for a running job, registered so
+// Cancel can cancel it.
+//
+// - ctx: The job's run context.
+// - e: The running enveloped job.
+//
+// Returns the cancelable context and it's release func, to be called once
+// the job is done.
+func (wp *workerPool) runContext(ctx context.Context, e *envelope) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	wp.statusMutex.Lock()
+	wp.cancels[e.id] = cancel
+	wp.statusMutex.Unlock()
+
+	return ctx, func() {
+		wp.statusMutex.Lock()
+		delete(wp.cancels, e.id)
+		wp.statusMutex.Unlock()
+
+		cancel()
+	}
+}
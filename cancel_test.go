@@ -0,0 +1,93 @@
+package thrall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type waitingJob struct {
+	JobContext
+}
+
+func (wj *waitingJob) Run() error {
+	select {
+	case <-wj.Context().Done():
+		return wj.Context().Err()
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Cancel succeed dropping a scheduled job", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		id, _ := Submit(&scheduleableJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Nil(Cancel(id))
+		assert.Equal(0, Stats().Scheduled)
+
+		status, _ := Status(id)
+		assert.Equal(StateCanceled, status.State)
+
+		close <- true
+	})
+
+	t.Run("when Cancel succeed canceling a running job's context", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(1))
+
+		id, _ := Submit(&waitingJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Nil(Cancel(id))
+		time.Sleep(10 * time.Millisecond)
+
+		status, _ := Status(id)
+		assert.Equal(StateCanceled, status.State)
+		assert.Empty(DeadLetters())
+
+		close <- true
+	})
+
+	t.Run("when Cancel succeed dropping a queued job", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		Submit(&slowJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		job := &testJob{}
+		id, _ := Submit(job)
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Nil(Cancel(id))
+		time.Sleep(60 * time.Millisecond)
+
+		status, _ := Status(id)
+		assert.Equal(StateCanceled, status.State)
+		assert.False(job.Executed.Load())
+
+		close <- true
+	})
+
+	t.Run("when Cancel fails", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		t.Run("due an unknown job", func(t *testing.T) {
+			assert.Equal(ErrJobNotFound, Cancel("foo"))
+		})
+
+		t.Run("due a finished job", func(t *testing.T) {
+			id, _ := Submit(&testJob{})
+			time.Sleep(10 * time.Millisecond)
+
+			assert.Equal(ErrJobFinished, Cancel(id))
+		})
+
+		close <- true
+	})
+}
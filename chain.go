@@ -0,0 +1,330 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrJobCanceled is the error of the chain steps which job has been canceled.
+var ErrJobCanceled = errors.New("job canceled")
+
+// Step is a chain's step, a func that gets the previous step's output as it's
+// input and returns it's own output. Build it with NewStep.
+type Step struct {
+	// Name is the step name, It's used as the step jobs type.
+	Name string
+
+	// Retries is the number of times that a failed step is run again, waiting
+	// Backoff between them.
+	Retries int
+	Backoff time.Duration
+
+	run func(ctx context.Context, input interface{}) (interface{}, error)
+}
+
+// StepResult is a chain step's final state.
+type StepResult struct {
+	Name string `json:"name"`
+
+	// JobID is the step's last attempt job ID, empty if the step has been
+	// skipped.
+	JobID    string   `json:"job_id,omitempty"`
+	Attempts int      `json:"attempts"`
+	State    JobState `json:"state"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// ChainResult is a chain run's final state, Output is the last step's output
+// and Err the failed step's error.
+type ChainResult struct {
+	Name   string       `json:"name"`
+	Output interface{}  `json:"output,omitempty"`
+	Err    error        `json:"-"`
+	Steps  []StepResult `json:"steps"`
+}
+
+// Chain is a linear pipeline of steps, every step is run through thrall with
+// the previous step's output. Build it with NewChain and Then, then Start it.
+type Chain struct {
+	// Name is the chain name, It's used to label it's logs.
+	Name string
+
+	steps      []*Step
+	onComplete func(ChainResult)
+}
+
+// ChainFuture is a started chain's pending result.
+type ChainFuture struct {
+	done   chan struct{}
+	result ChainResult
+}
+
+// chainJob runs a chain's step attempt on a worker.
+type chainJob struct {
+	JobContext
+
+	step   *Step
+	input  interface{}
+	output interface{}
+}
+
+// NewStep creates a typed chain step.
+//
+// - name: The step name.
+// - fn: The step func, it gets the previous step's output as it's input.
+// - opts: function option initializers, check WithStepRetries.
+//
+// Returns the chain step.
+func NewStep[In, Out any](name string, fn func(ctx context.Context, input In) (Out, error), opts ...func(*Step)) *Step {
+	step := &Step{
+		Name: name,
+		run: func(ctx context.Context, input interface{}) (interface{}, error) {
+			in, ok := input.(In)
+			if !ok && input != nil {
+				return nil, fmt.Errorf("step '%s' expects a %T input, got %T", name, in, input)
+			}
+
+			return fn(ctx, in)
+		},
+	}
+
+	for _, option := range opts {
+		option(step)
+	}
+
+	return step
+}
+
+// WithStepRetries is an optional func for NewStep, It does configure the
+// number of times that a failed step is run again.
+//
+// - retries: The max number of retries.
+// - backoff: The time to wait before every retry.
+//
+// Returns a optional configuration function.
+func WithStepRetries(retries int, backoff time.Duration) func(*Step) {
+	return func(s *Step) {
+		s.Retries = retries
+		s.Backoff = backoff
+	}
+}
+
+// NewChain creates a chain.
+//
+// - name: The chain name.
+// - steps: The chain's first steps.
+//
+// Returns the chain.
+func NewChain(name string, steps ...*Step) *Chain {
+	return &Chain{Name: name, steps: steps}
+}
+
+// Then appends a step to the chain.
+//
+// - step: The step, it gets the previous step's output.
+//
+// Returns the chain, to chain the builder calls.
+func (c *Chain) Then(step *Step) *Chain {
+	c.steps = append(c.steps, step)
+	return c
+}
+
+// OnComplete sets the chain's completion callback, it's called once with the
+// chain's result, right before the future is done.
+//
+// - callback: The completion callback.
+//
+// Returns the chain, to chain the builder calls.
+func (c *Chain) OnComplete(callback func(ChainResult)) *Chain {
+	c.onComplete = callback
+	return c
+}
+
+// Start runs the chain through thrall, the first step gets the given input.
+// A failed step stops the chain, it's following steps are skipped, and
+// canceling the context cancels the running step.
+//
+// - ctx: The chain's context.
+// - input: The first step's input.
+//
+// Returns the chain's future.
+func (c *Chain) Start(ctx context.Context, input interface{}) *ChainFuture {
+	f := &ChainFuture{done: make(chan struct{})}
+
+	go func() {
+		f.result = wp.runChain(ctx, c, input)
+
+		if c.onComplete != nil {
+			c.onComplete(f.result)
+		}
+
+		close(f.done)
+	}()
+
+	return f
+}
+
+// Done returns a channel that's closed once the chain is done.
+//
+// Returns the done channel.
+func (f *ChainFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the chain is done.
+//
+// Returns the chain's result.
+func (f *ChainFuture) Wait() ChainResult {
+	<-f.done
+	return f.result
+}
+
+// Await blocks until the chain is done or the context is canceled.
+//
+// - ctx: The wait's context.
+// - f: The chain's future.
+//
+// Returns the last step's typed output, or the failed step's error.
+func Await[T any](ctx context.Context, f *ChainFuture) (T, error) {
+	var output T
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return output, ctx.Err()
+	}
+
+	if f.result.Err != nil {
+		return output, f.result.Err
+	}
+
+	output, ok := f.result.Output.(T)
+	if !ok && f.result.Output != nil {
+		return output, fmt.Errorf("chain '%s' output is %T, not %T", f.result.Name,
+			f.result.Output, output)
+	}
+
+	return output, nil
+}
+
+// Name returns the step name as the chain job's type.
+//
+// Returns the step name.
+func (cj *chainJob) Name() string {
+	return cj.step.Name
+}
+
+// Run runs the step with it's input.
+//
+// Returns the step's error.
+func (cj *chainJob) Run() error {
+	output, err := cj.step.run(cj.Context(), cj.input)
+	cj.output = output
+
+	return err
+}
+
+// runChain runs every chain's step, one after the other.
+//
+// - ctx: The chain's context.
+// - c: The chain to run.
+// - input: The first step's input.
+//
+// Returns the chain's result.
+func (wp *workerPool) runChain(ctx context.Context, c *Chain, input interface{}) ChainResult {
+	result := ChainResult{Name: c.Name}
+	output := input
+
+	wp.Logger.Info("chain started", "pool", wp.Name, "chain", c.Name, "steps", len(c.steps))
+
+	for _, step := range c.steps {
+		if result.Err != nil {
+			result.Steps = append(result.Steps, StepResult{Name: step.Name, State: StateSkipped})
+			continue
+		}
+
+		stepResult, stepOutput, err := wp.runStep(ctx, step, output)
+		result.Steps = append(result.Steps, stepResult)
+
+		if err != nil {
+			result.Err = fmt.Errorf("chain '%s' step '%s' failed. Err: %w", c.Name, step.Name, err)
+			continue
+		}
+
+		output = stepOutput
+	}
+
+	if result.Err == nil {
+		result.Output = output
+	}
+
+	wp.Logger.Info("chain finished", "pool", wp.Name, "chain", c.Name, "error", result.Err)
+
+	return result
+}
+
+// runStep runs a chain's step through the workerPool, retrying it if it
+// fails.
+//
+// - ctx: The chain's context.
+// - step: The step to run.
+// - input: The step's input.
+//
+// Returns the step's result, it's output and it's last attempt's error.
+func (wp *workerPool) runStep(ctx context.Context, step *Step, input interface{}) (StepResult, interface{}, error) {
+	result := StepResult{Name: step.Name}
+
+	for {
+		result.Attempts++
+
+		job := &chainJob{step: step, input: input}
+		job.WithContext(ctx)
+
+		id, err := wp.submit(job)
+		if err != nil {
+			result.State, result.Error = StateFailed, err.Error()
+			return result, nil, err
+		}
+
+		finished := make(chan JobStatus, 1)
+		watching := wp.watch(id, func(status JobStatus) {
+			finished <- status
+		})
+		if !watching {
+			result.JobID, result.State, result.Error = id, StateFailed, ErrJobNotFound.Error()
+			return result, nil, ErrJobNotFound
+		}
+
+		var status JobStatus
+		select {
+		case status = <-finished:
+		case <-ctx.Done():
+			wp.cancel(id)
+			status = <-finished
+		}
+
+		result.JobID, result.State, result.Error = id, status.State, status.Error
+
+		switch {
+		case status.State == StateSucceeded:
+			return result, job.output, nil
+		case ctx.Err() != nil:
+			return result, nil, ctx.Err()
+		case status.State == StateCanceled:
+			return result, nil, ErrJobCanceled
+		case result.Attempts > step.Retries:
+			return result, nil, errors.New(status.Error)
+		}
+
+		wp.Logger.Info("chain step retried", "pool", wp.Name, "step", step.Name,
+			"job_id", id, "attempt", result.Attempts, "error", status.Error)
+
+		select {
+		case <-time.After(step.Backoff):
+		case <-ctx.Done():
+			return result, nil, ctx.Err()
+		}
+	}
+}
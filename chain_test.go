@@ -0,0 +1,113 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	assert := assert.New(t)
+
+	parse := NewStep("parse", func(ctx context.Context, input string) (int, error) {
+		return strconv.Atoi(input)
+	})
+
+	double := NewStep("double", func(ctx context.Context, input int) (int, error) {
+		return input * 2, nil
+	})
+
+	format := NewStep("format", func(ctx context.Context, input int) (string, error) {
+		return "result: " + strconv.Itoa(input), nil
+	})
+
+	t.Run("when Chain succeed passing every step's output to the next", func(t *testing.T) {
+		_, _, close := Init(2)
+		defer func() { close <- true }()
+
+		completed := make(chan ChainResult, 1)
+		f := NewChain("numbers", parse, double).
+			Then(format).
+			OnComplete(func(result ChainResult) { completed <- result }).
+			Start(context.Background(), "21")
+
+		output, err := Await[string](context.Background(), f)
+		assert.Nil(err)
+		assert.Equal("result: 42", output)
+
+		result := <-completed
+		assert.Len(result.Steps, 3)
+		assert.Equal(StateSucceeded, result.Steps[2].State)
+		assert.Equal(1, result.Steps[2].Attempts)
+
+		status, _ := Status(result.Steps[0].JobID)
+		assert.Equal("parse", status.Type)
+	})
+
+	t.Run("when Chain succeed retrying a failed step", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(2))
+		defer func() { close <- true }()
+
+		var calls atomic.Int32
+		flaky := NewStep("flaky", func(ctx context.Context, input int) (int, error) {
+			if calls.Add(1) < 3 {
+				return 0, errors.New("flaky failed")
+			}
+
+			return input + 1, nil
+		}, WithStepRetries(2, time.Millisecond))
+
+		result := NewChain("numbers", parse, flaky).Start(context.Background(), "1").Wait()
+		assert.Nil(result.Err)
+		assert.Equal(2, result.Output)
+		assert.Equal(3, result.Steps[1].Attempts)
+	})
+
+	t.Run("when Chain fails due a step that keeps failing", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(2))
+		defer func() { close <- true }()
+
+		result := NewChain("numbers", parse, double, format).
+			Start(context.Background(), "foo").
+			Wait()
+
+		assert.EqualError(result.Err,
+			`chain 'numbers' step 'parse' failed. Err: strconv.Atoi: parsing "foo": invalid syntax`)
+		assert.Nil(result.Output)
+		assert.Equal(StateFailed, result.Steps[0].State)
+		assert.Equal(StateSkipped, result.Steps[1].State)
+		assert.Equal(StateSkipped, result.Steps[2].State)
+	})
+
+	t.Run("when Chain fails due a mistyped step input", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		_, err := Await[string](context.Background(),
+			NewChain("numbers", double).Start(context.Background(), "21"))
+		assert.EqualError(err,
+			"chain 'numbers' step 'double' failed. Err: step 'double' expects a int input, got string")
+	})
+
+	t.Run("when Chain fails due the context cancelation", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		wait := NewStep("wait", func(ctx context.Context, input interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		result := NewChain("waiting", wait, format).Start(ctx, nil).Wait()
+		assert.ErrorIs(result.Err, context.DeadlineExceeded)
+		assert.Equal(StateSkipped, result.Steps[1].State)
+	})
+}
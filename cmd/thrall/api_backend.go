@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"github.com/jcleira/thrall/api"
+)
+
+// apiBackend operates thrall through it's HTTP API, the only queue is the
+// pool itself.
+type apiBackend struct {
+	base   string
+	client *http.Client
+}
+
+// newAPIBackend creates an HTTP API backend.
+//
+// - base: The HTTP API base URL.
+//
+// Returns the backend.
+func newAPIBackend(base string) *apiBackend {
+	return &apiBackend{
+		base:   strings.TrimSuffix(base, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Queues returns the pool's depth.
+func (b *apiBackend) Queues() ([]queue, error) {
+	var stats thrall.PoolStats
+	if err := b.do(http.MethodGet, "/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+
+	var dead []thrall.JobStatus
+	if err := b.do(http.MethodGet, "/dead-letters", nil, &dead); err != nil {
+		return nil, err
+	}
+
+	name := stats.Name
+	if stats.Paused {
+		name += " (paused)"
+	}
+
+	return []queue{{
+		Name:      name,
+		Queued:    stats.Queued + stats.Held,
+		Scheduled: stats.Scheduled,
+		Running:   stats.Busy,
+		Dead:      len(dead),
+	}}, nil
+}
+
+// Jobs returns the running and scheduled jobs, the HTTP API doesn't list the
+// queued ones.
+func (b *apiBackend) Jobs() ([]entry, error) {
+	var running, scheduled []thrall.JobStatus
+	if err := b.do(http.MethodGet, "/running", nil, &running); err != nil {
+		return nil, err
+	}
+
+	if err := b.do(http.MethodGet, "/scheduled", nil, &scheduled); err != nil {
+		return nil, err
+	}
+
+	return fromStatuses(append(running, scheduled...)), nil
+}
+
+// DeadLetters returns the pool's dead letters.
+func (b *apiBackend) DeadLetters() ([]entry, error) {
+	var dead []thrall.JobStatus
+	if err := b.do(http.MethodGet, "/dead-letters", nil, &dead); err != nil {
+		return nil, err
+	}
+
+	return fromStatuses(dead), nil
+}
+
+// Job returns a job's status.
+func (b *apiBackend) Job(id string) (entry, error) {
+	var status thrall.JobStatus
+	if err := b.do(http.MethodGet, "/jobs/"+url.PathEscape(id), nil, &status); err != nil {
+		return entry{}, err
+	}
+
+	return fromStatuses([]thrall.JobStatus{status})[0], nil
+}
+
+// Enqueue submits a job, the HTTP API can't schedule them.
+func (b *apiBackend) Enqueue(jobType string, payload []byte, when time.Time) (string, error) {
+	if !when.IsZero() {
+		return "", errors.New("the HTTP API doesn't schedule jobs")
+	}
+
+	var submitted api.Submitted
+	submission := api.Submission{Type: jobType, Payload: payload}
+	if err := b.do(http.MethodPost, "/jobs", submission, &submitted); err != nil {
+		return "", err
+	}
+
+	return submitted.ID, nil
+}
+
+// Retry submits a dead letter's job again, as a new job.
+func (b *apiBackend) Retry(id string) (string, error) {
+	var submitted api.Submitted
+	path := "/dead-letters/" + url.PathEscape(id) + "/retry"
+	if err := b.do(http.MethodPost, path, nil, &submitted); err != nil {
+		return "", err
+	}
+
+	return submitted.ID, nil
+}
+
+// Purge removes a dead letter.
+func (b *apiBackend) Purge(id string) error {
+	return b.do(http.MethodDelete, "/dead-letters/"+url.PathEscape(id), nil, nil)
+}
+
+// Cancel cancels a job.
+func (b *apiBackend) Cancel(id string) error {
+	return b.do(http.MethodPost, "/jobs/"+url.PathEscape(id)+"/cancel", nil, nil)
+}
+
+// Close releases the HTTP client's idle connections.
+func (b *apiBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
+
+// do sends a request to the HTTP API.
+//
+// - method: The request method.
+// - path: The request path, relative to the base URL.
+// - in: The request's JSON body, nil for none.
+// - out: The value the response's JSON body is decoded into, nil for none.
+//
+// Returns errNotFound on a 404 response, or an error if the request fails.
+func (b *apiBackend) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, b.base+path, body)
+	if err != nil {
+		return err
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		if resp.StatusCode == http.StatusNotFound {
+			return errNotFound
+		}
+
+		return fmt.Errorf("error calling '%s %s', %s. Err: %s", method, path, resp.Status, apiErr.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// fromStatuses converts the jobs statuses to entries.
+//
+// - statuses: The jobs statuses.
+//
+// Returns the entries.
+func fromStatuses(statuses []thrall.JobStatus) []entry {
+	entries := make([]entry, 0, len(statuses))
+
+	for _, status := range statuses {
+		entries = append(entries, entry{
+			ID:       status.ID,
+			Type:     status.Type,
+			State:    string(status.State),
+			Attempts: status.Attempts,
+			Schedule: status.Schedule,
+			Error:    status.Error,
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jcleira/thrall/store"
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	_ "modernc.org/sqlite"
+)
+
+// errNotFound is returned when a job doesn't exist.
+var errNotFound = errors.New("job not found")
+
+// The jobs states as listed by the commands, the HTTP API lists it's own
+// states as they are.
+const (
+	stateQueued    = "queued"
+	stateScheduled = "scheduled"
+	stateRunning   = "running"
+	stateDead      = "dead"
+)
+
+// queue is a queue's depth.
+type queue struct {
+	Name      string
+	Queued    int
+	Scheduled int
+	Running   int
+	Dead      int
+}
+
+// entry is a job as listed by the commands.
+type entry struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	State    string          `json:"state"`
+	Attempts int             `json:"attempts"`
+	Schedule time.Time       `json:"schedule,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+}
+
+// backend defines the operations on thrall's queues, either on a store
+// directly or through thrall's HTTP API.
+type backend interface {
+	// Queues returns the queues depth.
+	Queues() ([]queue, error)
+
+	// Jobs returns the queued, scheduled and running jobs.
+	Jobs() ([]entry, error)
+
+	// DeadLetters returns the dead letters.
+	DeadLetters() ([]entry, error)
+
+	// Job returns a job, or errNotFound if it doesn't exist.
+	Job(id string) (entry, error)
+
+	// Enqueue enqueues a job, scheduled at when if it's not zero, and returns
+	// it's ID.
+	Enqueue(jobType string, payload []byte, when time.Time) (string, error)
+
+	// Retry retries a dead letter and returns it's new ID.
+	Retry(id string) (string, error)
+
+	// Purge removes a dead letter.
+	Purge(id string) error
+
+	// Cancel cancels a queued or scheduled job.
+	Cancel(id string) error
+
+	// Close releases the backend's resources.
+	Close() error
+}
+
+// backendOptions are the flags selecting the backend.
+type backendOptions struct {
+	api         string
+	file        string
+	sqlDriver   string
+	sqlDSN      string
+	table       string
+	redisAddr   string
+	redisPrefix string
+}
+
+// register registers the backend flags.
+//
+// - flags: The flag set.
+//
+// Returns nothing.
+func (o *backendOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&o.api, "api", "", "thrall's HTTP API base URL, as http://localhost:8080/thrall")
+	flags.StringVar(&o.file, "file", "", "the FileStore's log path")
+	flags.StringVar(&o.sqlDriver, "sql-driver", "postgres", "the SQLStore's database: postgres, mysql or sqlite")
+	flags.StringVar(&o.sqlDSN, "sql-dsn", "", "the SQLStore's data source name")
+	flags.StringVar(&o.table, "table", "thrall_jobs", "the SQLStore's jobs table")
+	flags.StringVar(&o.redisAddr, "redis", "", "the RedisStore's server address")
+	flags.StringVar(&o.redisPrefix, "redis-prefix", "thrall", "the RedisStore's keys prefix")
+}
+
+// open opens the backend selected by the flags, exactly one is expected.
+//
+// Returns the backend or an error if none or several are selected, or the
+// backend can't be opened.
+func (o *backendOptions) open() (backend, error) {
+	selected := 0
+	for _, flag := range []string{o.api, o.file, o.sqlDSN, o.redisAddr} {
+		if flag != "" {
+			selected++
+		}
+	}
+
+	if selected != 1 {
+		return nil, errors.New("exactly one of -api, -file, -sql-dsn or -redis is required")
+	}
+
+	switch {
+	case o.api != "":
+		return newAPIBackend(o.api), nil
+
+	case o.file != "":
+		fs, err := store.NewFileStore(o.file)
+		if err != nil {
+			return nil, err
+		}
+
+		return newStoreBackend(fs, fs.Close)
+
+	case o.sqlDSN != "":
+		return o.openSQL()
+
+	default:
+		client := redis.NewClient(&redis.Options{Addr: o.redisAddr})
+		rs := store.NewRedisStore(client, store.WithKeyPrefix(o.redisPrefix))
+
+		return newStoreBackend(rs, client.Close)
+	}
+}
+
+// openSQL opens a SQLStore backend, the driver is registered as it's
+// dialect name, migrating it's table if needed.
+//
+// Returns the backend or an error if the database can't be opened.
+func (o *backendOptions) openSQL() (backend, error) {
+	dialects := map[string]store.Dialect{
+		"postgres": store.Postgres,
+		"mysql":    store.MySQL,
+		"sqlite":   store.SQLite,
+	}
+
+	dialect, exists := dialects[o.sqlDriver]
+	if !exists {
+		return nil, fmt.Errorf("sql driver '%s' not supported", o.sqlDriver)
+	}
+
+	db, err := sql.Open(o.sqlDriver, o.sqlDSN)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the '%s' database. Err: %v", o.sqlDriver, err)
+	}
+
+	s := store.NewSQLStore(db, store.WithDialect(dialect), store.WithTable(o.table))
+	if err := s.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return newStoreBackend(s, db.Close)
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// stdin is read by enqueue when the payload isn't given.
+var stdin io.Reader = os.Stdin
+
+// queuesCommand lists the queues depth.
+func queuesCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: queues takes no arguments", errUsage)
+	}
+
+	queues, err := b.Queues()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUEUE\tQUEUED\tSCHEDULED\tRUNNING\tDEAD")
+	for _, q := range queues {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", q.Name, q.Queued, q.Scheduled, q.Running, q.Dead)
+	}
+
+	return tw.Flush()
+}
+
+// jobsCommand lists the queued, scheduled and running jobs.
+func jobsCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: jobs takes no arguments", errUsage)
+	}
+
+	jobs, err := b.Jobs()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tSTATE\tATTEMPTS\tSCHEDULE")
+	for _, e := range jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", e.ID, e.Type, e.State, e.Attempts,
+			formatTime(e.Schedule))
+	}
+
+	return tw.Flush()
+}
+
+// deadLettersCommand lists the dead letters.
+func deadLettersCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: dead-letters takes no arguments", errUsage)
+	}
+
+	dead, err := b.DeadLetters()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tATTEMPTS\tERROR")
+	for _, e := range dead {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", e.ID, e.Type, e.Attempts, e.Error)
+	}
+
+	return tw.Flush()
+}
+
+// inspectCommand shows a job as indented JSON.
+func inspectCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: inspect takes a job ID", errUsage)
+	}
+
+	e, err := b.Job(args[0])
+	if err != nil {
+		return fmt.Errorf("error inspecting job '%s'. Err: %v", args[0], err)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(e)
+}
+
+// dumpCommand dumps every job, dead letters included, as JSON lines.
+func dumpCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: dump takes no arguments", errUsage)
+	}
+
+	jobs, err := b.Jobs()
+	if err != nil {
+		return err
+	}
+
+	dead, err := b.DeadLetters()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	for _, e := range append(jobs, dead...) {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enqueueCommand enqueues a job from it's JSON payload.
+func enqueueCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	flags := newFlagSet("enqueue")
+	in := flags.Duration("in", 0, "schedule the job after the given duration")
+	at := flags.String("at", "", "schedule the job at the given RFC3339 time")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return fmt.Errorf("%w: enqueue takes a job type and it's payload", errUsage)
+	}
+
+	var when time.Time
+	switch {
+	case *in != 0 && *at != "":
+		return fmt.Errorf("%w: -in and -at are exclusive", errUsage)
+	case *in != 0:
+		when = time.Now().Add(*in)
+	case *at != "":
+		var err error
+		if when, err = time.Parse(time.RFC3339, *at); err != nil {
+			return fmt.Errorf("%w: invalid -at time. Err: %v", errUsage, err)
+		}
+	}
+
+	var payload []byte
+	if flags.NArg() == 2 {
+		payload = []byte(flags.Arg(1))
+	} else {
+		var err error
+		if payload, err = io.ReadAll(stdin); err != nil {
+			return err
+		}
+	}
+
+	if !json.Valid(payload) {
+		return errors.New("the job's payload is not valid JSON")
+	}
+
+	id, err := b.Enqueue(flags.Arg(0), payload, when)
+	if err != nil {
+		return fmt.Errorf("error enqueuing job '%s'. Err: %v", flags.Arg(0), err)
+	}
+
+	fmt.Fprintln(out, id)
+
+	return nil
+}
+
+// retryCommand retries dead letters.
+func retryCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	return eachDeadLetter(b, "retry", args, func(id string) error {
+		retryID, err := b.Retry(id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "%s retried as %s\n", id, retryID)
+		return nil
+	})
+}
+
+// purgeCommand purges dead letters.
+func purgeCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	return eachDeadLetter(b, "purge", args, func(id string) error {
+		if err := b.Purge(id); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "%s purged\n", id)
+		return nil
+	})
+}
+
+// cancelCommand cancels queued or scheduled jobs.
+func cancelCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: cancel takes the jobs IDs", errUsage)
+	}
+
+	for _, id := range args {
+		if err := b.Cancel(id); err != nil {
+			return fmt.Errorf("error canceling job '%s'. Err: %v", id, err)
+		}
+
+		fmt.Fprintf(out, "%s canceled\n", id)
+	}
+
+	return nil
+}
+
+// tailCommand polls the jobs and prints their state changes until the context
+// is canceled.
+func tailCommand(ctx context.Context, b backend, args []string, out io.Writer) error {
+	flags := newFlagSet("tail")
+	interval := flags.Duration("interval", time.Second, "the jobs polling interval")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *interval <= 0 {
+		return fmt.Errorf("%w: -interval must be positive", errUsage)
+	}
+
+	seen, err := snapshot(b)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := snapshot(b)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().Format(time.RFC3339)
+
+		for id, e := range current {
+			if previous, exists := seen[id]; !exists || previous.State != e.State {
+				fmt.Fprintf(out, "%s  %-10s %s  %s\n", now, e.State, e.ID, e.Type)
+			}
+		}
+
+		// The jobs that left the listings are looked up for their final state,
+		// the stores forget the jobs once they are acked.
+		for id, previous := range seen {
+			if _, exists := current[id]; exists {
+				continue
+			}
+
+			state := "done"
+			if e, err := b.Job(id); err == nil {
+				state = e.State
+			}
+
+			fmt.Fprintf(out, "%s  %-10s %s  %s\n", now, state, id, previous.Type)
+		}
+
+		seen = current
+	}
+}
+
+// eachDeadLetter runs an action on the dead letters given as arguments, or on
+// all of them with -all.
+//
+// - b: The backend.
+// - name: The command name.
+// - args: The command arguments.
+// - action: The action run on each dead letter.
+//
+// Returns the first action's error.
+func eachDeadLetter(b backend, name string, args []string, action func(id string) error) error {
+	flags := newFlagSet(name)
+	all := flags.Bool("all", false, "apply to every dead letter")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	ids := flags.Args()
+	if *all == (len(ids) > 0) {
+		return fmt.Errorf("%w: %s takes either the dead letters IDs or -all", errUsage, name)
+	}
+
+	if *all {
+		dead, err := b.DeadLetters()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range dead {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	for _, id := range ids {
+		if err := action(id); err != nil {
+			return fmt.Errorf("error on %s of dead letter '%s'. Err: %v", name, id, err)
+		}
+	}
+
+	return nil
+}
+
+// snapshot lists the jobs and dead letters by their ID.
+//
+// - b: The backend.
+//
+// Returns the jobs by ID or an error if they can't be listed.
+func snapshot(b backend) (map[string]entry, error) {
+	jobs, err := b.Jobs()
+	if err != nil {
+		return nil, err
+	}
+
+	dead, err := b.DeadLetters()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]entry, len(jobs)+len(dead))
+	for _, e := range append(jobs, dead...) {
+		entries[e.ID] = e
+	}
+
+	return entries, nil
+}
+
+// newFlagSet creates a subcommand flag set, it's errors are reported by run.
+//
+// - name: The subcommand name.
+//
+// Returns the flag set.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	return flags
+}
+
+// formatTime formats a time as RFC3339, or "-" if it's zero.
+//
+// - t: The time.
+//
+// Returns the formatted time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
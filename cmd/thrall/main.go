@@ -0,0 +1,130 @@
+/*
+Command thrall operates thrall's persistent queues.
+
+It does list the queues and their jobs, inspect and dump the jobs, enqueue a
+job from it's JSON payload, retry or purge the dead letters, cancel the queued
+or scheduled jobs and tail the jobs events. It operates against a store
+directly, as a FileStore, a SQLStore or a RedisStore, or against thrall's HTTP
+API as served by the api or dashboard packages.
+
+Usage:
+
+	thrall [flags] <command> [args]
+
+Run "thrall -h" for the flags and commands.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// usage is the commands usage, printed after the flags defaults.
+const usage = `
+Commands:
+  queues                         List the queues depth.
+  jobs                           List the queued, scheduled and running jobs.
+  inspect ID                     Show a job, with it's payload.
+  dump                           Dump every job, dead letters included, as JSON lines.
+  enqueue [-in D|-at T] TYPE [PAYLOAD]
+                                 Enqueue a job from it's JSON payload, read from
+                                 stdin if it's not given.
+  dead-letters                   List the dead letters.
+  retry [-all] [ID...]           Retry dead letters.
+  purge [-all] [ID...]           Purge dead letters.
+  cancel ID...                   Cancel queued or scheduled jobs.
+  tail [-interval D]             Tail the jobs events.
+`
+
+// errUsage is returned for an invalid command line, the usage is printed.
+var errUsage = errors.New("invalid usage")
+
+// command runs a thrall subcommand.
+type command func(ctx context.Context, b backend, args []string, out io.Writer) error
+
+// commands are the thrall subcommands by their name.
+var commands = map[string]command{
+	"queues":       queuesCommand,
+	"jobs":         jobsCommand,
+	"inspect":      inspectCommand,
+	"dump":         dumpCommand,
+	"enqueue":      enqueueCommand,
+	"dead-letters": deadLettersCommand,
+	"retry":        retryCommand,
+	"purge":        purgeCommand,
+	"cancel":       cancelCommand,
+	"tail":         tailCommand,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses the command line, opens the backend and runs the command.
+//
+// - ctx: The command context, it's canceled on interrupt.
+// - args: The command line arguments.
+// - in: The standard input.
+// - out: The standard output.
+// - errOut: The standard error.
+//
+// Returns the exit code.
+func run(ctx context.Context, args []string, in io.Reader, out, errOut io.Writer) int {
+	flags := flag.NewFlagSet("thrall", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	flags.Usage = func() {
+		fmt.Fprintln(errOut, "Usage: thrall [flags] <command> [args]\n\nFlags:")
+		flags.PrintDefaults()
+		fmt.Fprint(errOut, usage)
+	}
+
+	var opts backendOptions
+	opts.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	cmd, exists := commands[flags.Arg(0)]
+	if !exists {
+		fmt.Fprintf(errOut, "command '%s' not found\n", flags.Arg(0))
+		flags.Usage()
+		return 2
+	}
+
+	b, err := opts.open()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	defer b.Close()
+
+	stdin = in
+
+	if err := cmd(ctx, b, flags.Args()[1:], out); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(errOut, err)
+			flags.Usage()
+			return 2
+		}
+
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	return 0
+}
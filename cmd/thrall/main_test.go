@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"github.com/jcleira/thrall/api"
+	"github.com/jcleira/thrall/store"
+	"github.com/stretchr/testify/assert"
+)
+
+type testJob struct {
+	Name string `json:"name"`
+}
+
+func (j *testJob) Run() error {
+	return nil
+}
+
+// thrallCmd runs a thrall command line.
+func thrallCmd(args ...string) (int, string, string) {
+	var out, errOut bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(`{"name":"stdin"}`), &out, &errOut)
+
+	return code, out.String(), errOut.String()
+}
+
+func TestStoreBackend(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "jobs.log")
+
+	t.Run("when thrall succeed enqueuing and listing jobs", func(t *testing.T) {
+		code, out, _ := thrallCmd("-file", path, "enqueue", "testJob", `{"name":"foo"}`)
+		assert.Equal(0, code)
+		assert.Len(strings.TrimSpace(out), 32)
+
+		code, _, _ = thrallCmd("-file", path, "enqueue", "-in", "1h", "testJob")
+		assert.Equal(0, code)
+
+		code, out, _ = thrallCmd("-file", path, "queues")
+		assert.Equal(0, code)
+		assert.Regexp(`testJob\s+1\s+1\s+0\s+0`, out)
+
+		code, out, _ = thrallCmd("-file", path, "dump")
+		assert.Equal(0, code)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		assert.Len(lines, 2)
+
+		var e entry
+		assert.Nil(json.Unmarshal([]byte(lines[0]), &e))
+		assert.Equal(stateQueued, e.State)
+		assert.JSONEq(`{"name":"foo"}`, string(e.Payload))
+	})
+
+	t.Run("when thrall succeed retrying, purging and canceling jobs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dead.log")
+
+		fs, err := store.NewFileStore(path)
+		assert.Nil(err)
+
+		for i := 0; i < 2; i++ {
+			assert.Nil(fs.Enqueue(&store.Job{ID: string(rune('a' + i)), Type: "testJob"}))
+			job, err := fs.Reserve(time.Minute)
+			assert.Nil(err)
+			assert.Nil(fs.Bury(job.ID, job.Token, "boom"))
+		}
+		fs.Close()
+
+		code, out, _ := thrallCmd("-file", path, "dead-letters")
+		assert.Equal(0, code)
+		assert.Contains(out, "boom")
+
+		code, out, _ = thrallCmd("-file", path, "retry", "a")
+		assert.Equal(0, code)
+		assert.Equal("a retried as a\n", out)
+
+		code, out, _ = thrallCmd("-file", path, "purge", "-all")
+		assert.Equal(0, code)
+		assert.Equal("b purged\n", out)
+
+		code, out, _ = thrallCmd("-file", path, "inspect", "a")
+		assert.Equal(0, code)
+		assert.Contains(out, `"state": "queued"`)
+
+		code, out, _ = thrallCmd("-file", path, "cancel", "a")
+		assert.Equal(0, code)
+		assert.Equal("a canceled\n", out)
+
+		code, _, errOut := thrallCmd("-file", path, "inspect", "a")
+		assert.Equal(1, code)
+		assert.Contains(errOut, "job not found")
+	})
+
+	t.Run("when thrall fails due an invalid usage", func(t *testing.T) {
+		code, _, errOut := thrallCmd("-file", path, "foo")
+		assert.Equal(2, code)
+		assert.Contains(errOut, "command 'foo' not found")
+
+		code, _, _ = thrallCmd("-file", path, "retry")
+		assert.Equal(2, code)
+
+		code, _, errOut = thrallCmd("queues")
+		assert.Equal(1, code)
+		assert.Contains(errOut, "exactly one of")
+
+		code, _, errOut = thrallCmd("-file", path, "enqueue", "testJob", "{")
+		assert.Equal(1, code)
+		assert.Contains(errOut, "not valid JSON")
+	})
+}
+
+func TestAPIBackend(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, close := thrall.Init(1, thrall.WithName("api"))
+	defer func() { close <- true }()
+
+	types := thrall.NewTypeRegistry()
+	types.Register("testJob", func() thrall.Runnable { return &testJob{} })
+
+	server := httptest.NewServer(api.NewHandler(types))
+	defer server.Close()
+
+	t.Run("when thrall succeed enqueuing jobs through the HTTP API", func(t *testing.T) {
+		code, out, _ := thrallCmd("-api", server.URL, "enqueue", "testJob", `{"name":"foo"}`)
+		assert.Equal(0, code)
+
+		id := strings.TrimSpace(out)
+		time.Sleep(10 * time.Millisecond)
+
+		code, out, _ = thrallCmd("-api", server.URL, "inspect", id)
+		assert.Equal(0, code)
+		assert.Contains(out, `"state": "succeeded"`)
+
+		code, out, _ = thrallCmd("-api", server.URL, "queues")
+		assert.Equal(0, code)
+		assert.Regexp(`api\s+0\s+0\s+0\s+0`, out)
+	})
+
+	t.Run("when thrall fails due the HTTP API not scheduling jobs", func(t *testing.T) {
+		code, _, errOut := thrallCmd("-api", server.URL, "enqueue", "-in", "1h", "testJob")
+		assert.Equal(1, code)
+		assert.Contains(errOut, "doesn't schedule jobs")
+	})
+}
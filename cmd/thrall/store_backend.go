@@ -0,0 +1,199 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jcleira/thrall/store"
+)
+
+// storeBackend operates a store directly, the queues are the stored job
+// types.
+type storeBackend struct {
+	store store.Store
+	admin store.Admin
+	close func() error
+}
+
+// newStoreBackend creates a store backend.
+//
+// - s: The store, it must implement store.Admin.
+// - close: The func releasing the store's resources.
+//
+// Returns the backend or an error if the store doesn't implement store.Admin.
+func newStoreBackend(s store.Store, close func() error) (backend, error) {
+	admin, ok := s.(store.Admin)
+	if !ok {
+		close()
+		return nil, errors.New("the store doesn't support the dead letters")
+	}
+
+	return &storeBackend{store: s, admin: admin, close: close}, nil
+}
+
+// Queues returns the queues depth by job type.
+func (b *storeBackend) Queues() ([]queue, error) {
+	jobs, err := b.Jobs()
+	if err != nil {
+		return nil, err
+	}
+
+	dead, err := b.DeadLetters()
+	if err != nil {
+		return nil, err
+	}
+
+	queues := make(map[string]*queue)
+	for _, e := range append(jobs, dead...) {
+		q, exists := queues[e.Type]
+		if !exists {
+			q = &queue{Name: e.Type}
+			queues[e.Type] = q
+		}
+
+		switch e.State {
+		case stateQueued:
+			q.Queued++
+		case stateScheduled:
+			q.Scheduled++
+		case stateRunning:
+			q.Running++
+		case stateDead:
+			q.Dead++
+		}
+	}
+
+	result := make([]queue, 0, len(queues))
+	for _, q := range queues {
+		result = append(result, *q)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// Jobs returns the stored jobs but the dead letters.
+func (b *storeBackend) Jobs() ([]entry, error) {
+	jobs, err := b.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return toEntries(jobs, time.Now()), nil
+}
+
+// DeadLetters returns the stored dead letters.
+func (b *storeBackend) DeadLetters() ([]entry, error) {
+	jobs, err := b.admin.DeadLetters()
+	if err != nil {
+		return nil, err
+	}
+
+	return toEntries(jobs, time.Now()), nil
+}
+
+// Job returns a stored job, dead letters included.
+func (b *storeBackend) Job(id string) (entry, error) {
+	for _, list := range []func() ([]entry, error){b.Jobs, b.DeadLetters} {
+		entries, err := list()
+		if err != nil {
+			return entry{}, err
+		}
+
+		for _, e := range entries {
+			if e.ID == id {
+				return e, nil
+			}
+		}
+	}
+
+	return entry{}, errNotFound
+}
+
+// Enqueue stores a job.
+func (b *storeBackend) Enqueue(jobType string, payload []byte, when time.Time) (string, error) {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	job := &store.Job{
+		ID:       hex.EncodeToString(id),
+		Type:     jobType,
+		Payload:  payload,
+		Enqueued: time.Now(),
+	}
+
+	var err error
+	if when.IsZero() {
+		err = b.store.Enqueue(job)
+	} else {
+		err = b.store.Schedule(job, when)
+	}
+
+	return job.ID, err
+}
+
+// Retry revives a dead letter, keeping it's ID.
+func (b *storeBackend) Retry(id string) (string, error) {
+	return id, b.admin.Revive(id)
+}
+
+// Purge removes a dead letter.
+func (b *storeBackend) Purge(id string) error {
+	return b.admin.Purge(id)
+}
+
+// Cancel removes a queued or scheduled job.
+func (b *storeBackend) Cancel(id string) error {
+	return b.admin.Remove(id)
+}
+
+// Close releases the store's resources.
+func (b *storeBackend) Close() error {
+	return b.close()
+}
+
+// toEntries converts the stored jobs to entries.
+//
+// - jobs: The stored jobs.
+// - now: The time the jobs state is evaluated at.
+//
+// Returns the entries.
+func toEntries(jobs []*store.Job, now time.Time) []entry {
+	entries := make([]entry, 0, len(jobs))
+
+	for _, job := range jobs {
+		e := entry{
+			ID:       job.ID,
+			Type:     job.Type,
+			State:    stateQueued,
+			Attempts: job.Attempts,
+			Schedule: job.Schedule,
+			Error:    job.Error,
+			Payload:  job.Payload,
+		}
+
+		switch {
+		case !job.Buried.IsZero():
+			e.State = stateDead
+		case job.Reserved && job.Lease.After(now):
+			e.State = stateRunning
+		case job.Schedule.After(now):
+			e.State = stateScheduled
+		}
+
+		// The payloads encoded by a codec other than JSON are listed as a
+		// base64 JSON string.
+		if !json.Valid(e.Payload) {
+			e.Payload, _ = json.Marshal(job.Payload)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
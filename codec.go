@@ -0,0 +1,168 @@
+package thrall
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec defines how the jobs are encoded to be stored or transmitted, and how
+// they are decoded back. The Encode() func returns the job's type name and
+// payload, the Decode() func returns the Runnable for a type name and payload.
+type Codec interface {
+	Encode(job Runnable) (string, []byte, error)
+	Decode(name string, payload []byte) (Runnable, error)
+}
+
+// Marshaler defines how a job payload is serialized, It's the hook to plug
+// any serialization format into a RegistryCodec, as protobuf by wrapping
+// proto.Marshal and proto.Unmarshal for jobs that are proto.Message.
+type Marshaler interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// RegistryCodec is a Codec that uses a TypeRegistry to get the jobs type
+// names and constructors, and a Marshaler to serialize the jobs payload.
+type RegistryCodec struct {
+	Types     *TypeRegistry
+	Marshaler Marshaler
+}
+
+// NewCodec creates a RegistryCodec for any Marshaler.
+//
+// - types: The registry of the job types to encode and decode.
+// - marshaler: The payload Marshaler.
+//
+// Returns the RegistryCodec.
+func NewCodec(types *TypeRegistry, marshaler Marshaler) *RegistryCodec {
+	return &RegistryCodec{Types: types, Marshaler: marshaler}
+}
+
+// NewJSONCodec creates a RegistryCodec that serializes the jobs as JSON.
+//
+// - types: The registry of the job types to encode and decode.
+//
+// Returns the RegistryCodec.
+func NewJSONCodec(types *TypeRegistry) *RegistryCodec {
+	return NewCodec(types, JSONMarshaler{})
+}
+
+// NewGobCodec creates a RegistryCodec that serializes the jobs with
+// encoding/gob.
+//
+// - types: The registry of the job types to encode and decode.
+//
+// Returns the RegistryCodec.
+func NewGobCodec(types *TypeRegistry) *RegistryCodec {
+	return NewCodec(types, GobMarshaler{})
+}
+
+// Encode encodes a job.
+//
+// - job: The job to encode.
+//
+// Returns the job's type name and payload, or an error if the job type is
+// not registered or the job can't be serialized.
+func (rc *RegistryCodec) Encode(job Runnable) (string, []byte, error) {
+	name, err := rc.Types.Name(job)
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload, err := rc.Marshaler.Marshal(job)
+	if err != nil {
+		return "", nil, fmt.Errorf("job type '%s' not encoded. Err: %v", name, err)
+	}
+
+	return name, payload, nil
+}
+
+// Decode decodes a job.
+//
+// - name: The job's type name.
+// - payload: The job's payload.
+//
+// Returns the decoded job, or an error if the job type is not registered or
+// the payload can't be deserialized.
+func (rc *RegistryCodec) Decode(name string, payload []byte) (Runnable, error) {
+	job, err := rc.Types.New(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rc.Marshaler.Unmarshal(payload, job); err != nil {
+		return nil, fmt.Errorf("job type '%s' not decoded. Err: %v", name, err)
+	}
+
+	return job, nil
+}
+
+// JSONMarshaler is a Marshaler that serializes the jobs as JSON.
+type JSONMarshaler struct{}
+
+// Marshal serializes v as JSON.
+func (JSONMarshaler) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal deserializes the JSON data into v.
+func (JSONMarshaler) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobMarshaler is a Marshaler that serializes the jobs with encoding/gob.
+type GobMarshaler struct{}
+
+// Marshal serializes v with encoding/gob.
+func (GobMarshaler) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal deserializes the encoding/gob data into v.
+func (GobMarshaler) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// encodedJob is the wire format of a job encoded by EncodeJob.
+type encodedJob struct {
+	Type    string `json:"type"`
+	Payload []byte `json:"payload"`
+}
+
+// EncodeJob encodes a job into a single byte slice, carrying both the job's
+// type name and payload, ready to be stored or transmitted.
+//
+// - codec: The Codec to encode the job with.
+// - job: The job to encode.
+//
+// Returns the encoded job or an error if the job can't be encoded.
+func EncodeJob(codec Codec, job Runnable) ([]byte, error) {
+	name, payload, err := codec.Encode(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encodedJob{Type: name, Payload: payload})
+}
+
+// DecodeJob decodes a job encoded by EncodeJob into the right Runnable.
+//
+// - codec: The Codec to decode the job with.
+// - data: The encoded job.
+//
+// Returns the decoded job or an error if the job can't be decoded.
+func DecodeJob(codec Codec, data []byte) (Runnable, error) {
+	var encoded encodedJob
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("job not decoded. Err: %v", err)
+	}
+
+	return codec.Decode(encoded.Type, encoded.Payload)
+}
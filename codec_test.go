@@ -0,0 +1,92 @@
+package thrall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type emailJob struct {
+	To      string
+	Subject string
+}
+
+func (ej *emailJob) Run() error {
+	return nil
+}
+
+func newTestTypes() *TypeRegistry {
+	types := NewTypeRegistry()
+	types.Register("email", func() Runnable { return &emailJob{} })
+
+	return types
+}
+
+func TestTypeRegistry(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Register succeed registering a job type", func(t *testing.T) {
+		types := newTestTypes()
+
+		name, err := types.Name(&emailJob{})
+		assert.Nil(err)
+		assert.Equal("email", name)
+
+		job, err := types.New("email")
+		assert.Nil(err)
+		assert.IsType(&emailJob{}, job)
+		assert.Equal([]string{"email"}, types.Names())
+	})
+
+	t.Run("when Register fails registering a job type", func(t *testing.T) {
+		t.Run("due empty name", func(t *testing.T) {
+			err := NewTypeRegistry().Register("", func() Runnable { return &emailJob{} })
+			assert.Equal("job type's name should not be empty", err.Error())
+		})
+
+		t.Run("due registering the name twice", func(t *testing.T) {
+			err := newTestTypes().Register("email", func() Runnable { return &testJob{} })
+			assert.Equal("job type 'email' already registered", err.Error())
+		})
+
+		t.Run("due registering the job type twice", func(t *testing.T) {
+			err := newTestTypes().Register("mail", func() Runnable { return &emailJob{} })
+			assert.Equal("job type '*thrall.emailJob' already registered as 'email'", err.Error())
+		})
+	})
+
+	t.Run("when New fails due a not registered name", func(t *testing.T) {
+		_, err := newTestTypes().New("foo")
+		assert.Equal("job type 'foo' not registered", err.Error())
+	})
+
+	t.Run("when Name fails due a not registered job type", func(t *testing.T) {
+		_, err := newTestTypes().Name(&testJob{})
+		assert.Equal("job type '*thrall.testJob' not registered", err.Error())
+	})
+}
+
+func TestCodecs(t *testing.T) {
+	assert := assert.New(t)
+
+	for name, codec := range map[string]Codec{
+		"json": NewJSONCodec(newTestTypes()),
+		"gob":  NewGobCodec(newTestTypes()),
+	} {
+		t.Run("when the "+name+" codec succeed encoding and decoding a job", func(t *testing.T) {
+			job := &emailJob{To: "foo@bar.com", Subject: "foo"}
+
+			data, err := EncodeJob(codec, job)
+			assert.Nil(err)
+
+			decoded, err := DecodeJob(codec, data)
+			assert.Nil(err)
+			assert.Equal(job, decoded)
+		})
+
+		t.Run("when the "+name+" codec fails encoding a not registered job", func(t *testing.T) {
+			_, _, err := codec.Encode(&testJob{})
+			assert.Equal("job type '*thrall.testJob' not registered", err.Error())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package thrall
+
+import "context"
+
+// Contextual defines an interface that should be implemented for that jobs
+// that would need a context.Context, the producer sets the job's context
+// before sending it to thrall, then thrall replaces it with the job's
+// execution context, carrying the trace context, right before running the
+// job. Embed JobContext to implement it.
+type Contextual interface {
+	Context() context.Context
+	WithContext(ctx context.Context)
+}
+
+// JobContext is an embeddable Contextual implementation.
+type JobContext struct {
+	ctx context.Context
+}
+
+// Context returns the job's context.
+//
+// Returns the job's context or context.Background() if none was set.
+func (jc *JobContext) Context() context.Context {
+	if jc.ctx == nil {
+		return context.Background()
+	}
+
+	return jc.ctx
+}
+
+// WithContext sets the job's context.
+//
+// - ctx: The job's context.
+//
+// Returns nothing.
+func (jc *JobContext) WithContext(ctx context.Context) {
+	jc.ctx = ctx
+}
+
+// jobContext returns the given job's context.
+//
+// - job: The job to get the context from.
+//
+// Returns the Contextual job's context or context.Background().
+func jobContext(job Runnable) context.Context {
+	if contextual, ok := job.(Contextual); ok {
+		return contextual.Context()
+	}
+
+	return context.Background()
+}
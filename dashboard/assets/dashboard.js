@@ -0,0 +1,158 @@
+// thrall's admin dashboard, it polls the HTTP API served under api/.
+(function () {
+  "use strict";
+
+  var refreshInterval = 2000;
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  function escape(value) {
+    return String(value === undefined || value === null ? "" : value)
+      .replace(/&/g, "&amp;")
+      .replace(/</g, "&lt;")
+      .replace(/>/g, "&gt;")
+      .replace(/"/g, "&quot;");
+  }
+
+  function time(value) {
+    if (!value || value.indexOf("0001-") === 0) {
+      return "";
+    }
+
+    return new Date(value).toLocaleString();
+  }
+
+  function request(method, path) {
+    return fetch("api/" + path, { method: method }).then(function (res) {
+      if (res.status === 204) {
+        return null;
+      }
+
+      return res.json().then(function (body) {
+        if (!res.ok) {
+          throw new Error(body.error || res.statusText);
+        }
+
+        return body;
+      });
+    });
+  }
+
+  function showError(err) {
+    $("error").textContent = err ? err.message : "";
+    $("error").hidden = !err;
+  }
+
+  function action(label, method, path) {
+    return '<button data-method="' + method + '" data-path="' + escape(path) + '">' +
+      label + "</button>";
+  }
+
+  function rows(id, statuses, columns, empty) {
+    if (statuses.length === 0) {
+      $(id).innerHTML = '<tr><td class="empty" colspan="' + columns.length + '">' +
+        empty + "</td></tr>";
+      return;
+    }
+
+    $(id).innerHTML = statuses.map(function (status) {
+      return "<tr>" + columns.map(function (column) {
+        return "<td>" + column(status) + "</td>";
+      }).join("") + "</tr>";
+    }).join("");
+  }
+
+  function renderStats(stats) {
+    var limiter = stats.limiter;
+    var usage = limiter.capacity > 0 ? limiter.in_use / limiter.capacity : 0;
+
+    $("pool").textContent = stats.name;
+    $("workers").textContent = stats.workers;
+    $("busy").textContent = stats.busy;
+    $("queued").textContent = stats.queued;
+    $("held").textContent = stats.held;
+    $("scheduled-count").textContent = stats.scheduled;
+    $("state").textContent = stats.paused ? "paused" :
+      stats.paused_types.length > 0 ? "paused: " + stats.paused_types.join(", ") : "running";
+
+    $("limiter-name").textContent = limiter.name;
+    $("limiter-bar").style.width = Math.min(usage, 1) * 100 + "%";
+    $("limiter").textContent = limiter.in_use + " / " + limiter.capacity +
+      " in use, " + limiter.denied + " denied";
+  }
+
+  function id(status) {
+    return '<code title="' + escape(status.id) + '">' + escape(status.id.slice(0, 8)) + "</code>";
+  }
+
+  function type(status) {
+    return escape(status.type);
+  }
+
+  function refresh() {
+    Promise.all([
+      request("GET", "stats"),
+      request("GET", "running"),
+      request("GET", "scheduled"),
+      request("GET", "failures"),
+      request("GET", "dead-letters")
+    ]).then(function (res) {
+      renderStats(res[0]);
+
+      rows("running", res[1], [id, type,
+        function (s) { return escape(s.worker); },
+        function (s) { return time(s.started); },
+        function (s) { return s.attempts; },
+        function (s) { return action("Cancel", "POST", "jobs/" + s.id + "/cancel"); }
+      ], "No running jobs");
+
+      rows("scheduled", res[2], [id, type,
+        function (s) { return time(s.schedule); },
+        function (s) { return action("Cancel", "POST", "jobs/" + s.id + "/cancel"); }
+      ], "No scheduled jobs");
+
+      rows("failures", res[3], [id, type,
+        function (s) { return escape(s.state); },
+        function (s) { return s.attempts; },
+        function (s) { return time(s.finished); },
+        function (s) { return '<span class="error">' + escape(s.error) + "</span>"; }
+      ], "No failures");
+
+      rows("dead-letters", res[4], [id, type,
+        function (s) { return s.attempts; },
+        function (s) { return time(s.finished); },
+        function (s) { return '<span class="error">' + escape(s.error) + "</span>"; },
+        function (s) { return action("Retry", "POST", "dead-letters/" + s.id + "/retry"); }
+      ], "No dead letters");
+
+      showError(null);
+    }).catch(showError);
+  }
+
+  function pauseAction(path) {
+    var jobType = $("pause-type").value.trim();
+    if (jobType) {
+      path += "?type=" + encodeURIComponent(jobType);
+    }
+
+    request("POST", path).then(refresh).catch(showError);
+  }
+
+  document.addEventListener("click", function (event) {
+    var button = event.target.closest("button[data-path]");
+    if (!button) {
+      return;
+    }
+
+    button.disabled = true;
+    request(button.dataset.method, button.dataset.path).then(refresh).catch(showError);
+  });
+
+  $("pause").addEventListener("click", function () { pauseAction("pause"); });
+  $("resume").addEventListener("click", function () { pauseAction("resume"); });
+
+  refresh();
+  setInterval(refresh, refreshInterval);
+})();
@@ -0,0 +1,50 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/jcleira/thrall"
+	"github.com/jcleira/thrall/api"
+)
+
+// assets are the dashboard static files.
+//
+//go:embed assets
+var assets embed.FS
+
+// Handler is thrall's admin dashboard http.Handler, it serves the dashboard
+// page at it's root and thrall's HTTP API, that the page polls, under api/.
+// Mount it with http.StripPrefix to serve it under a path, the path must end
+// with a slash as the page requests are relative.
+type Handler struct {
+	// API is the HTTP API served under api/.
+	API *api.Handler
+
+	mux *http.ServeMux
+}
+
+// NewHandler creates thrall's admin dashboard Handler.
+//
+// - types: The registry of the job types that can be submitted to the API.
+//
+// Returns the Handler.
+func NewHandler(types *thrall.TypeRegistry) *Handler {
+	h := &Handler{
+		API: api.NewHandler(types),
+		mux: http.NewServeMux(),
+	}
+
+	static, _ := fs.Sub(assets, "assets")
+
+	h.mux.Handle("/api/", http.StripPrefix("/api", h.API))
+	h.mux.Handle("/", http.FileServer(http.FS(static)))
+
+	return h
+}
+
+// ServeHTTP serves thrall's admin dashboard.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jcleira/thrall"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	return recorder
+}
+
+func TestHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, close := thrall.Init(1)
+	defer func() { close <- true }()
+
+	h := http.StripPrefix("/admin", NewHandler(thrall.NewTypeRegistry()))
+
+	t.Run("when the dashboard succeed serving it's embedded assets", func(t *testing.T) {
+		recorder := serve(h, http.MethodGet, "/admin/")
+		assert.Equal(http.StatusOK, recorder.Code)
+		assert.Contains(recorder.Body.String(), `<script src="dashboard.js"></script>`)
+
+		recorder = serve(h, http.MethodGet, "/admin/dashboard.js")
+		assert.Equal(http.StatusOK, recorder.Code)
+		assert.Contains(recorder.Header().Get("Content-Type"), "javascript")
+
+		recorder = serve(h, http.MethodGet, "/admin/dashboard.css")
+		assert.Equal(http.StatusOK, recorder.Code)
+	})
+
+	t.Run("when the dashboard succeed serving the HTTP API", func(t *testing.T) {
+		recorder := serve(h, http.MethodGet, "/admin/api/stats")
+		assert.Equal(http.StatusOK, recorder.Code)
+
+		var stats thrall.PoolStats
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &stats))
+		assert.Equal(1, stats.Workers)
+
+		recorder = serve(h, http.MethodPost, "/admin/api/pause")
+		assert.Equal(http.StatusOK, recorder.Code)
+		assert.True(thrall.Stats().Paused)
+
+		thrall.Resume()
+	})
+
+	t.Run("when the dashboard fails due an unknown asset", func(t *testing.T) {
+		recorder := serve(h, http.MethodGet, "/admin/foo.js")
+		assert.Equal(http.StatusNotFound, recorder.Code)
+	})
+}
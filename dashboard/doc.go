@@ -0,0 +1,10 @@
+/*
+Package dashboard provides an admin web dashboard for thrall's workers.
+
+It does expose an http.Handler, with it's assets embedded, that shows the pool
+workers and the jobs they are running, the queue depth, the scheduled jobs,
+the limiter utilization, the recent failures and the dead letters. It does
+also cancel jobs, retry dead letters and pause or resume the pool, through the
+api package Handler served under it's api/ path.
+*/
+package dashboard
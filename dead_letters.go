@@ -0,0 +1,75 @@
+package thrall
+
+// Retry submits a dead letter's job again, as a new job, and removes it from
+// the dead letters.
+//
+// - id: The dead letter's job ID.
+//
+// Returns the new job ID, ErrJobNotFound if the job is not a dead letter or
+// an error if thrall is closed.
+func Retry(id string) (string, error) {
+	return wp.retry(id)
+}
+
+// Purge removes a dead letter.
+//
+// - id: The dead letter's job ID.
+//
+// Returns ErrJobNotFound if the job is not a dead letter.
+func Purge(id string) error {
+	return wp.purge(id)
+}
+
+// retry submits a dead letter's job again.
+//
+// - id: The dead letter's job ID.
+//
+// Returns the new job ID, or an error if the job is not a dead letter.
+func (wp *workerPool) retry(id string) (string, error) {
+	dl, exists := wp.removeDeadLetter(id)
+	if !exists {
+		return "", ErrJobNotFound
+	}
+
+	newID, err := wp.submit(dl.job)
+	if err != nil {
+		return "", err
+	}
+
+	wp.Logger.Info("dead letter retried", "job_id", id, "job_type", dl.status.Type,
+		"retry_id", newID)
+
+	return newID, nil
+}
+
+// purge removes a dead letter.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (wp *workerPool) purge(id string) error {
+	if _, exists := wp.removeDeadLetter(id); !exists {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
+
+// removeDeadLetter removes a dead letter.
+//
+// - id: The dead letter's job ID.
+//
+// Returns the removed dead letter and whether it existed.
+func (wp *workerPool) removeDeadLetter(id string) (deadLetter, bool) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	for i, dl := range wp.dead {
+		if dl.status.ID == id {
+			wp.dead = append(wp.dead[:i:i], wp.dead[i+1:]...)
+			return dl, true
+		}
+	}
+
+	return deadLetter{}, false
+}
@@ -0,0 +1,58 @@
+package thrall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Retry succeed running a dead letter's job again", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(2))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&errorJob{})
+		time.Sleep(10 * time.Millisecond)
+		assert.Len(DeadLetters(), 1)
+
+		retryID, err := Retry(id)
+		assert.Nil(err)
+		assert.NotEqual(id, retryID)
+
+		time.Sleep(10 * time.Millisecond)
+
+		status, _ := Status(retryID)
+		assert.Equal(StateFailed, status.State)
+		assert.Equal("errorJob", status.Type)
+
+		dead := DeadLetters()
+		assert.Len(dead, 1)
+		assert.Equal(retryID, dead[0].ID)
+	})
+
+	t.Run("when Purge succeed removing a dead letter", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&errorJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Nil(Purge(id))
+		assert.Empty(DeadLetters())
+		assert.Equal(ErrJobNotFound, Purge(id))
+	})
+
+	t.Run("when Retry fails due a job that is not a dead letter", func(t *testing.T) {
+		_, _, close := Init(1)
+		defer func() { close <- true }()
+
+		id, _ := Submit(&testJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := Retry(id)
+		assert.Equal(ErrJobNotFound, err)
+	})
+}
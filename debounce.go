@@ -0,0 +1,217 @@
+package thrall
+
+import "time"
+
+// Debounced defines an interface that could be implemented by jobs that should
+// run once their submissions stop, the DebounceKey() func returns the job's
+// debounce key, within it's job type, and DebounceDelay() the time to wait
+// after the key's last submission. The submissions received while the key's
+// job is waiting are coalesced into it, the job keeps it's ID but runs the
+// latest payload.
+type Debounced interface {
+	DebounceKey() string
+	DebounceDelay() time.Duration
+}
+
+// Throttled defines an interface that could be implemented by jobs that should
+// run at most once per period, the ThrottleKey() func returns the job's
+// throttle key, within it's job type, and ThrottlePeriod() the min time
+// between the key's runs. A submission received before the period has passed
+// is scheduled for the period's end, and the ones received while it's waiting
+// are coalesced into it, as the Debounced jobs are.
+type Throttled interface {
+	ThrottleKey() string
+	ThrottlePeriod() time.Duration
+}
+
+// delayedKey is a debounced or throttled key's state.
+type delayedKey struct {
+	// pending is the key's waiting job, due it's execution time and latest
+	// the payload it would run instead, coalesced from the later submissions.
+	pending *envelope
+	due     time.Time
+	latest  Runnable
+
+	// next is a throttled key's next allowed run time, it's zero for the
+	// debounced keys.
+	next time.Time
+}
+
+// delay debounces or throttles a received job, the job is coalesced into the
+// key's waiting job if any, or it's due time is set so it waits on the
+// scheduler. The debounced and throttled jobs are not stored, they wait in
+// memory.
+//
+// - e: The received enveloped job.
+//
+// Returns the ID of the job that would run the received one, that's it's own
+// ID unless it has been coalesced into the key's waiting job.
+func (wp *workerPool) delay(e *envelope) string {
+	key, wait, throttled := delayOf(e.job)
+	if key == "" {
+		return e.id
+	}
+
+	metric := "workerpool_job_debounced"
+	if throttled {
+		metric = "workerpool_job_throttled"
+	}
+
+	now := time.Now()
+
+	wp.delayMutex.Lock()
+
+	state, exists := wp.delays[key]
+	if !exists {
+		state = &delayedKey{}
+		wp.delays[key] = state
+	}
+
+	if state.pending != nil {
+		state.latest = e.job
+		if !throttled {
+			state.due = now.Add(wait)
+		}
+
+		pending, due := state.pending, state.due
+		wp.delayMutex.Unlock()
+
+		wp.endPhase(e)
+		wp.IncMetric(e.job, metric)
+		wp.Logger.Debug("job coalesced", jobFields(e, "coalesced_into", pending.id,
+			"schedule", due)...)
+		wp.updateStatus(pending, func(status *JobStatus) {
+			status.Schedule = due
+		})
+
+		return pending.id
+	}
+
+	due := now.Add(wait)
+	if throttled {
+		if !state.next.After(now) {
+			state.next = now.Add(wait)
+			wp.delayMutex.Unlock()
+
+			return e.id
+		}
+
+		due, state.next = state.next, state.next.Add(wait)
+	}
+
+	state.pending, state.due = e, due
+	e.due, e.delayKey = due, key
+
+	wp.delayMutex.Unlock()
+
+	if throttled {
+		wp.IncMetric(e.job, metric)
+	}
+
+	return e.id
+}
+
+// dueDelayed takes a debounced or throttled job out of it's key once it's
+// due, swapping it's payload for the latest coalesced one. It's called by the
+// scheduler right before dispatching the job.
+//
+// - e: The scheduled enveloped job.
+//
+// Returns the job's new due time and true if it has been debounced since it
+// was scheduled, so it must keep waiting.
+func (wp *workerPool) dueDelayed(e *envelope) (time.Time, bool) {
+	if e.delayKey == "" {
+		return time.Time{}, false
+	}
+
+	wp.delayMutex.Lock()
+	defer wp.delayMutex.Unlock()
+
+	state, exists := wp.delays[e.delayKey]
+	if !exists || state.pending != e {
+		return time.Time{}, false
+	}
+
+	if state.due.After(time.Now()) {
+		return state.due, true
+	}
+
+	if state.latest != nil {
+		e.job = state.latest
+	}
+
+	wp.clearDelayed(e.delayKey, state)
+
+	return time.Time{}, false
+}
+
+// releaseDelayed takes a finished debounced or throttled job out of it's key,
+// as it happens when the job is canceled while waiting.
+//
+// - e: The finished enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) releaseDelayed(e *envelope) {
+	if e.delayKey == "" {
+		return
+	}
+
+	wp.delayMutex.Lock()
+	defer wp.delayMutex.Unlock()
+
+	if state, exists := wp.delays[e.delayKey]; exists && state.pending == e {
+		wp.clearDelayed(e.delayKey, state)
+	}
+}
+
+// clearDelayed clears a key's waiting job, the debounced keys are dropped as
+// they have no state left. It must be called holding the delayMutex.
+//
+// - key: The debounced or throttled key.
+// - state: The key's state.
+//
+// Returns nothing.
+func (wp *workerPool) clearDelayed(key string, state *delayedKey) {
+	state.pending, state.latest = nil, nil
+
+	if state.next.IsZero() {
+		delete(wp.delays, key)
+	}
+}
+
+// expireDelayed drops the throttled keys which period has passed and have no
+// waiting job.
+//
+// Returns nothing.
+func (wp *workerPool) expireDelayed() {
+	wp.delayMutex.Lock()
+	defer wp.delayMutex.Unlock()
+
+	now := time.Now()
+	for key, state := range wp.delays {
+		if state.pending == nil && !state.next.After(now) {
+			delete(wp.delays, key)
+		}
+	}
+}
+
+// delayOf returns a Debounced or Throttled job's key, scoped by it's job type,
+// and it's delay or period.
+//
+// - job: The job.
+//
+// Returns the key, empty if the job is neither Debounced or Throttled, the
+// wait and true if the job is Throttled.
+func delayOf(job Runnable) (string, time.Duration, bool) {
+	if debounced, ok := job.(Debounced); ok {
+		return "debounce/" + jobType(job) + "/" + debounced.DebounceKey(),
+			debounced.DebounceDelay(), false
+	}
+
+	if throttled, ok := job.(Throttled); ok {
+		return "throttle/" + jobType(job) + "/" + throttled.ThrottleKey(),
+			throttled.ThrottlePeriod(), true
+	}
+
+	return "", 0, false
+}
@@ -0,0 +1,141 @@
+package thrall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type debouncedJob struct {
+	Key   string
+	Value string
+	Delay time.Duration
+	runs  chan string
+}
+
+func (dj *debouncedJob) Run() error {
+	dj.runs <- dj.Value
+	return nil
+}
+
+func (dj *debouncedJob) DebounceKey() string {
+	return dj.Key
+}
+
+func (dj *debouncedJob) DebounceDelay() time.Duration {
+	return dj.Delay
+}
+
+type throttledJob struct {
+	Key    string
+	Value  string
+	Period time.Duration
+	runs   chan string
+}
+
+func (tj *throttledJob) Run() error {
+	tj.runs <- tj.Value
+	return nil
+}
+
+func (tj *throttledJob) ThrottleKey() string {
+	return tj.Key
+}
+
+func (tj *throttledJob) ThrottlePeriod() time.Duration {
+	return tj.Period
+}
+
+func TestDebounce(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Debounced succeed running once after the last submission", func(t *testing.T) {
+		_, _, close := Init(1)
+		defer func() { close <- true }()
+
+		runs := make(chan string, 3)
+		delay := 200 * time.Millisecond
+
+		other, _ := Submit(&debouncedJob{Key: "bar", Value: "other", Delay: delay, runs: runs})
+		first, _ := Submit(&debouncedJob{Key: "foo", Value: "first", Delay: delay, runs: runs})
+		assert.NotEqual(first, other)
+		time.Sleep(120 * time.Millisecond)
+
+		second, _ := Submit(&debouncedJob{Key: "foo", Value: "second", Delay: delay, runs: runs})
+		assert.Equal(first, second)
+
+		// The first submission's delay has passed, but not the second's.
+		time.Sleep(120 * time.Millisecond)
+		wp.enqueueScheduled()
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal("other", <-runs)
+		assert.Empty(runs)
+
+		status, _ := Status(first)
+		assert.Equal(StateScheduled, status.State)
+
+		time.Sleep(150 * time.Millisecond)
+		wp.enqueueScheduled()
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal("second", <-runs)
+
+		status, _ = Status(first)
+		assert.Equal(StateSucceeded, status.State)
+		assert.Empty(wp.delays)
+	})
+
+	t.Run("when Debounced succeed releasing a canceled job's key", func(t *testing.T) {
+		_, _, close := Init(1)
+		defer func() { close <- true }()
+
+		runs := make(chan string, 1)
+		job := &debouncedJob{Key: "foo", Delay: time.Hour, runs: runs}
+
+		first, _ := Submit(job)
+		time.Sleep(10 * time.Millisecond)
+		assert.Nil(Cancel(first))
+
+		second, _ := Submit(job)
+		assert.NotEqual(first, second)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Throttled succeed running at most once per period", func(t *testing.T) {
+		_, _, close := Init(1)
+		defer func() { close <- true }()
+
+		runs := make(chan string, 3)
+		period := 200 * time.Millisecond
+
+		first, _ := Submit(&throttledJob{Key: "foo", Value: "first", Period: period, runs: runs})
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal("first", <-runs)
+
+		second, _ := Submit(&throttledJob{Key: "foo", Value: "second", Period: period, runs: runs})
+		third, _ := Submit(&throttledJob{Key: "foo", Value: "third", Period: period, runs: runs})
+		assert.NotEqual(first, second)
+		assert.Equal(second, third)
+
+		status, _ := Status(second)
+		assert.Equal(StateScheduled, status.State)
+
+		wp.enqueueScheduled()
+		time.Sleep(10 * time.Millisecond)
+		assert.Empty(runs)
+
+		time.Sleep(period)
+		wp.enqueueScheduled()
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal("third", <-runs)
+
+		time.Sleep(period)
+		wp.expireDelayed()
+		assert.Empty(wp.delays)
+	})
+}
@@ -0,0 +1,61 @@
+package thrall
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// envelope wraps a Runnable while it travels from the workerPool to the
+// workers, it keeps track of the job's lifecycle times so the workerPool can
+// report how long the job has been waiting.
+type envelope struct {
+	id  string
+	job Runnable
+
+	// ready is the time when the job became ready to be run, that's when it
+	// has been received or, for Scheduleable jobs, when it has been enqueued
+	// by the scheduler.
+	ready time.Time
+
+	// schedule is the programmed execution time for Scheduleable jobs, It's
+	// zero for any other job.
+	schedule time.Time
+
+	// due is the execution time of the debounced and throttled jobs, and
+	// delayKey their key, they are zero for any other job.
+	due      time.Time
+	delayKey string
+
+	// stored is set for the jobs reserved from the workerPool Store, attempts
+	// is the number of times that they have been reserved and token their
+	// current reservation's token.
+	stored   bool
+	attempts int
+	token    string
+
+	// stopHeartbeat stops the stored jobs reservation lease heartbeat, it's
+	// started once the job is reserved and stopped once the job is settled.
+	stopHeartbeat func()
+
+	// ctx is the producer's context, the parent of all the job's spans.
+	ctx context.Context
+
+	// span is the job's current lifecycle phase span, and phase it's name.
+	span  trace.Span
+	phase string
+}
+
+// newEnvelope wraps a received job and starts it's enqueue phase, the job's
+// spans are children of the Contextual job's context.
+//
+// - job: The received job.
+//
+// Returns the enveloped job.
+func (wp *workerPool) newEnvelope(job Runnable) *envelope {
+	e := &envelope{id: newID(), job: job, ctx: jobContext(job)}
+	wp.startPhase(e, phaseEnqueue)
+
+	return e
+}
@@ -0,0 +1,79 @@
+package thrall
+
+// Hooks are the workerPool lifecycle callbacks, every hook is optional and
+// it's called from the workerPool goroutines, so they should not block.
+type Hooks struct {
+	// OnEnqueue is called when a job is received by the workerPool.
+	OnEnqueue func(job Runnable)
+
+	// OnStart is called right before a job is run.
+	OnStart func(job Runnable)
+
+	// OnSuccess is called when a job run succeed.
+	OnSuccess func(job Runnable)
+
+	// OnFailure is called when a job run fails or times out, err is a JobError.
+	OnFailure func(job Runnable, err error)
+
+	// OnRetry is called when a job is returned to the queue to be tried again,
+	// as it happens when the limiter denies running it.
+	OnRetry func(job Runnable)
+
+	// OnDiscard is called when a job is discarded without being run, as it
+	// happens with the scheduled and paused jobs on shutdown.
+	OnDiscard func(job Runnable)
+}
+
+// WithHooks is an optional func for thrall's init, It does configure the
+// workerPool lifecycle hooks.
+//
+// - hooks: The lifecycle hooks.
+//
+// Returns a optional configuration function.
+func WithHooks(hooks Hooks) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.Hooks = hooks
+	}
+}
+
+// enqueue calls the OnEnqueue hook if configured.
+func (h Hooks) enqueue(job Runnable) {
+	if h.OnEnqueue != nil {
+		h.OnEnqueue(job)
+	}
+}
+
+// start calls the OnStart hook if configured.
+func (h Hooks) start(job Runnable) {
+	if h.OnStart != nil {
+		h.OnStart(job)
+	}
+}
+
+// success calls the OnSuccess hook if configured.
+func (h Hooks) success(job Runnable) {
+	if h.OnSuccess != nil {
+		h.OnSuccess(job)
+	}
+}
+
+// failure calls the OnFailure hook if configured.
+func (h Hooks) failure(job Runnable, err error) {
+	if h.OnFailure != nil {
+		h.OnFailure(job, err)
+	}
+}
+
+// retry calls the OnRetry hook if configured.
+func (h Hooks) retry(job Runnable) {
+	if h.OnRetry != nil {
+		h.OnRetry(job)
+	}
+}
+
+// discard calls the OnDiscard hook if configured.
+func (h Hooks) discard(job Runnable) {
+	if h.OnDiscard != nil {
+		h.OnDiscard(job)
+	}
+}
@@ -0,0 +1,74 @@
+package thrall
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHooks(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		mutex  sync.Mutex
+		events []string
+	)
+
+	record := func(event string) func(Runnable) {
+		return func(job Runnable) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			events = append(events, event+":"+jobType(job))
+		}
+	}
+
+	hooks := Hooks{
+		OnEnqueue: record("enqueue"),
+		OnStart:   record("start"),
+		OnSuccess: record("success"),
+		OnFailure: func(job Runnable, err error) {
+			record("failure")(job)
+		},
+		OnRetry:   record("retry"),
+		OnDiscard: record("discard"),
+	}
+
+	t.Run("when the hooks succeed reporting the jobs lifecycle", func(t *testing.T) {
+		events = nil
+		queue, _, close := Init(1, WithHooks(hooks), WithOnError(func(JobError) {}))
+
+		queue <- &testJob{}
+		time.Sleep(10 * time.Millisecond)
+		queue <- &errorJob{}
+		time.Sleep(10 * time.Millisecond)
+		queue <- &scheduleableJob{}
+		time.Sleep(10 * time.Millisecond)
+
+		close <- true
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		assert.Equal([]string{
+			"enqueue:testJob", "start:testJob", "success:testJob",
+			"enqueue:errorJob", "start:errorJob", "failure:errorJob",
+			"enqueue:scheduleableJob", "discard:scheduleableJob",
+		}, events)
+		mutex.Unlock()
+	})
+
+	t.Run("when the hooks succeed reporting the rate limited jobs", func(t *testing.T) {
+		events = nil
+		queue, _, close := Init(1, WithHooks(hooks), WithMaxLimiter(0))
+
+		queue <- &testJob{}
+		time.Sleep(10 * time.Millisecond)
+
+		close <- true
+
+		mutex.Lock()
+		assert.Contains(events, "retry:testJob")
+		mutex.Unlock()
+	})
+}
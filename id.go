@@ -0,0 +1,16 @@
+package thrall
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// newID generates a random job ID.
+//
+// Returns a 32 chars hex encoded ID.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,118 @@
+package thrall
+
+import "fmt"
+
+// JobError is the error reported for every failed or timed out job, it's sent
+// to the errors channel and to the OnError handler.
+type JobError struct {
+	JobID    string
+	JobType  string
+	WorkerID int
+	Job      Runnable
+
+	// RemoteWorker is the ID of the remote worker that run the job, if any.
+	RemoteWorker string
+
+	// Err is the error returned by the job's Run, nil on timeouts.
+	Err error
+
+	// Timeout is set if the job didn't finish on time.
+	Timeout bool
+}
+
+// Error returns the JobError message.
+//
+// Returns the error message.
+func (je JobError) Error() string {
+	if je.Timeout {
+		return fmt.Sprintf("job timeout (%f sec) on worker %d", jobTimeout.Seconds(), je.WorkerID)
+	}
+
+	if je.RemoteWorker != "" {
+		return fmt.Sprintf("job error on remote worker %s. Err: %v", je.RemoteWorker, je.Err)
+	}
+
+	return fmt.Sprintf("job error on worker %d. Err: %v", je.WorkerID, je.Err)
+}
+
+// Unwrap returns the error returned by the job's Run.
+//
+// Returns the job's error.
+func (je JobError) Unwrap() error {
+	return je.Err
+}
+
+// WithOnError is an optional func for thrall's init, It does configure a
+// handler that would be called for every job error. Once a handler is
+// configured the errors channel only receives errors if WithErrorsBuffer is
+// also configured, so the errors channel may be ignored. The handler is called
+// from the job's goroutine, it should not block.
+//
+// - handler: The job errors handler.
+//
+// Returns a optional configuration function.
+func WithOnError(handler func(JobError)) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.onError = handler
+	}
+}
+
+// WithErrorsBuffer is an optional func for thrall's init, It does configure a
+// bounded errors channel that never blocks the workers, if the channel is full
+// the error is dropped and counted on the "workerpool_job_errors_dropped"
+// metric.
+//
+// - size: The errors channel buffer size.
+//
+// Returns a optional configuration function.
+func WithErrorsBuffer(size int) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.errors = make(chan error, size)
+		wp.errorsBuffered = true
+	}
+}
+
+// reportError reports a job error to the OnError handler and to the errors
+// channel. The errors channel send blocks the worker unless the errors
+// channel is buffered.
+//
+// - je: The job error to report.
+//
+// Returns nothing.
+func (wp *workerPool) reportError(je JobError) {
+	if wp.onError != nil {
+		wp.onError(je)
+	}
+
+	if wp.errorsBuffered {
+		select {
+		case wp.errors <- je:
+		default:
+			wp.errorsDropped.Add(1)
+			wp.IncMetric(je.Job, "workerpool_job_errors_dropped")
+			wp.Logger.Warn("job error dropped", "job_id", je.JobID, "job_type", je.JobType,
+				"error", je)
+		}
+
+		return
+	}
+
+	if wp.onError == nil {
+		wp.errors <- je
+	}
+}
+
+// newJobError creates the JobError for an enveloped job.
+//
+// - e: The failed enveloped job.
+// - workerID: The worker that run the job.
+//
+// Returns the JobError, set it's Err or Timeout.
+func newJobError(e *envelope, workerID int) JobError {
+	return JobError{
+		JobID:    e.id,
+		JobType:  jobType(e.job),
+		WorkerID: workerID,
+		Job:      e.job,
+	}
+}
@@ -0,0 +1,72 @@
+package thrall
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOnError(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when OnError succeed handling errors without blocking the workers", func(t *testing.T) {
+		var (
+			mutex   sync.Mutex
+			handled []JobError
+		)
+
+		queue, _, close := Init(1, WithOnError(func(je JobError) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			handled = append(handled, je)
+		}))
+
+		queue <- &errorJob{}
+		queue <- &errorJob{}
+
+		var job testJob
+		queue <- &job
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		assert.Len(handled, 2)
+		assert.Equal("errorJob", handled[0].JobType)
+		assert.Equal(1, handled[0].WorkerID)
+		assert.NotEmpty(handled[0].JobID)
+		assert.Equal("error!", errors.Unwrap(handled[0]).Error())
+		mutex.Unlock()
+
+		assert.True(job.Executed.Load())
+
+		close <- true
+	})
+}
+
+func TestWithErrorsBuffer(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when the errors channel is full the errors are dropped", func(t *testing.T) {
+		queue, errs, close := Init(1, WithErrorsBuffer(1))
+
+		for i := 0; i < 3; i++ {
+			queue <- &errorJob{}
+		}
+
+		var job testJob
+		queue <- &job
+		time.Sleep(10 * time.Millisecond)
+
+		assert.True(job.Executed.Load())
+		assert.Len(errs, 1)
+		assert.Equal(uint64(2), wp.errorsDropped.Load())
+
+		var je JobError
+		assert.True(errors.As(<-errs, &je))
+		assert.Equal("job error on worker 1. Err: error!", je.Error())
+
+		close <- true
+	})
+}
@@ -1,7 +1,27 @@
 package limiters
 
+// Limiter defines the rules that a Job must follow before being run by a
+// Worker, the Worker would Adquire the limiter before running the Job and
+// Release it once the Job is finished.
 type Limiter interface {
 	Init()
 	Adquire() bool
 	Release()
+	Stats() Stats
+}
+
+// Stats is a point in time snapshot of a Limiter's usage, it has been created
+// to inspect how close the Workers are from hitting the configured limits.
+type Stats struct {
+	// Name is the limiter's kind name, as "max" or "per_second".
+	Name string `json:"name"`
+
+	// InUse is the number of slots currently taken on the limiter.
+	InUse int `json:"in_use"`
+
+	// Capacity is the max number of slots that the limiter allows.
+	Capacity int `json:"capacity"`
+
+	// Denied is the number of Adquire calls rejected since the limiter creation.
+	Denied int `json:"denied"`
 }
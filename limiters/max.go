@@ -6,8 +6,9 @@ import "sync"
 // jobs limit on the Workers, It's not used right now and has been created by
 // error while trying to create the PerSecond limiter.
 type Max struct {
-	Max  int
-	Busy int
+	Max    int
+	Busy   int
+	denied int
 	sync.Mutex
 }
 
@@ -25,6 +26,7 @@ func (m *Max) Adquire() bool {
 	defer m.Unlock()
 
 	if m.Busy >= m.Max {
+		m.denied++
 		return false
 	}
 
@@ -40,3 +42,18 @@ func (m *Max) Release() {
 	defer m.Unlock()
 	m.Busy--
 }
+
+// Stats returns the Max limiter current usage.
+//
+// Returns a Stats snapshot.
+func (m *Max) Stats() Stats {
+	m.Lock()
+	defer m.Unlock()
+
+	return Stats{
+		Name:     "max",
+		InUse:    m.Busy,
+		Capacity: m.Max,
+		Denied:   m.denied,
+	}
+}
@@ -44,3 +44,23 @@ func TestMaxRelease(t *testing.T) {
 		assert.Equal(0, max.Busy)
 	})
 }
+
+func TestMaxStats(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when max limiter stats succeed reporting usage", func(t *testing.T) {
+		max := Max{
+			Max:  1,
+			Busy: 0,
+		}
+
+		assert.True(max.Adquire())
+		assert.False(max.Adquire())
+
+		stats := max.Stats()
+		assert.Equal("max", stats.Name)
+		assert.Equal(1, stats.InUse)
+		assert.Equal(1, stats.Capacity)
+		assert.Equal(1, stats.Denied)
+	})
+}
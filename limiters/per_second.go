@@ -14,6 +14,7 @@ type PerSecond struct {
 	Finished int
 	Starts   time.Time
 	Ends     time.Time
+	denied   int
 	sync.Mutex
 }
 
@@ -41,6 +42,7 @@ func (ps *PerSecond) Adquire() bool {
 	defer ps.Unlock()
 
 	if ps.Started+ps.Finished >= ps.Max {
+		ps.denied++
 		return false
 	}
 
@@ -58,3 +60,19 @@ func (ps *PerSecond) Release() {
 	ps.Started--
 	ps.Finished++
 }
+
+// Stats returns the PerSecond limiter current usage, the in use slots are the
+// already Started plus the already Finished Jobs on the current second.
+//
+// Returns a Stats snapshot.
+func (ps *PerSecond) Stats() Stats {
+	ps.Lock()
+	defer ps.Unlock()
+
+	return Stats{
+		Name:     "per_second",
+		InUse:    ps.Started + ps.Finished,
+		Capacity: ps.Max,
+		Denied:   ps.denied,
+	}
+}
@@ -71,3 +71,24 @@ func TestPerSecondRelease(t *testing.T) {
 		assert.Equal(0, perSecond.Max)
 	})
 }
+
+func TestPerSecondStats(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when per second limiter stats succeed reporting usage", func(t *testing.T) {
+		perSecond := PerSecond{
+			Max:      3,
+			Started:  1,
+			Finished: 1,
+		}
+
+		assert.True(perSecond.Adquire())
+		assert.False(perSecond.Adquire())
+
+		stats := perSecond.Stats()
+		assert.Equal("per_second", stats.Name)
+		assert.Equal(3, stats.InUse)
+		assert.Equal(3, stats.Capacity)
+		assert.Equal(1, stats.Denied)
+	})
+}
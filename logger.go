@@ -0,0 +1,54 @@
+package thrall
+
+// Logger defines the structured logger used by thrall to report the jobs
+// lifecycle events, every func receives a message and a list of alternating
+// keys and values as "job_id", "1f4e...". *slog.Logger implements it, so use
+// WithLogger(slog.Default()) to log on the log/slog default logger.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// NopLogger is a Logger that discards every event, It's the default Logger
+// when no Logger is configured.
+type NopLogger struct{}
+
+// Debug does nothing but it's necesary to implement the Logger interface.
+func (NopLogger) Debug(msg string, keyvals ...interface{}) {}
+
+// Info does nothing but it's necesary to implement the Logger interface.
+func (NopLogger) Info(msg string, keyvals ...interface{}) {}
+
+// Warn does nothing but it's necesary to implement the Logger interface.
+func (NopLogger) Warn(msg string, keyvals ...interface{}) {}
+
+// Error does nothing but it's necesary to implement the Logger interface.
+func (NopLogger) Error(msg string, keyvals ...interface{}) {}
+
+// WithLogger is an optional func for thrall's init, It does configure the
+// Logger where thrall would report the jobs lifecycle events.
+//
+// - logger: The Logger, as a *slog.Logger.
+//
+// Returns a optional configuration function.
+func WithLogger(logger Logger) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.Logger = logger
+	}
+}
+
+// jobFields returns the job's log fields, the job ID and type, followed by the
+// given keyvals.
+//
+// - e: The enveloped job.
+// - keyvals: Additional alternating keys and values.
+//
+// Returns the job's log fields.
+func jobFields(e *envelope, keyvals ...interface{}) []interface{} {
+	return append([]interface{}{
+		"job_id", e.id,
+		"job_type", jobType(e.job),
+	}, keyvals...)
+}
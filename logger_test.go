@@ -0,0 +1,58 @@
+package thrall
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer safe to be written by the workers goroutines.
+type syncBuffer struct {
+	buf bytes.Buffer
+	sync.Mutex
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.Lock()
+	defer sb.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.Lock()
+	defer sb.Unlock()
+	return sb.buf.String()
+}
+
+func TestWithLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when WithLogger succeed logging the jobs lifecycle on slog", func(t *testing.T) {
+		var buf syncBuffer
+		logger := slog.New(slog.NewTextHandler(&buf,
+			&slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		queue, errors, close := Init(1, WithLogger(logger))
+
+		queue <- &testJob{}
+		queue <- &errorJob{}
+		<-errors
+		time.Sleep(10 * time.Millisecond)
+		close <- true
+		time.Sleep(10 * time.Millisecond)
+
+		logs := buf.String()
+		assert.Contains(logs, `msg="workerpool started" pool=default workers=1`)
+		assert.Contains(logs, `msg="job started" job_id=`)
+		assert.Contains(logs, `job_type=testJob worker_id=1`)
+		assert.Contains(logs, `msg="job finished"`)
+		assert.Contains(logs, `msg="job failed"`)
+		assert.Contains(logs, `job_type=errorJob worker_id=1`)
+		assert.Contains(logs, `error=error!`)
+		assert.Contains(logs, `msg="workerpool shutdown" pool=default`)
+	})
+}
@@ -25,14 +25,18 @@ func (r *Registry) NewCounters(names ...string) error {
 			return fmt.Errorf("counter '%s' already registered", name)
 		}
 
-		r.Counters[name] = prometheus.NewCounter(
+		collector, err := r.register(prometheus.NewCounter(
 			prometheus.CounterOpts{
-				Name: name,
-				Help: name,
+				Namespace: r.Namespace,
+				Name:      name,
+				Help:      name,
 			},
-		)
+		))
+		if err != nil {
+			return fmt.Errorf("counter '%s' not registered. Err: %v", name, err)
+		}
 
-		prometheus.MustRegister(r.Counters[name])
+		r.Counters[name] = collector.(prometheus.Counter)
 	}
 
 	return nil
@@ -48,7 +52,7 @@ func (r *Registry) CloseCounter(name string) error {
 		return fmt.Errorf("counter '%s' not registered", name)
 	}
 
-	if unregistered := prometheus.Unregister(r.Counters[name]); !unregistered {
+	if unregistered := r.unregister(r.Counters[name]); !unregistered {
 		return fmt.Errorf("counter '%s' not unregistered", name)
 	}
 
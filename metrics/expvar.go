@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// Expvar is a Sink that publishes the metrics as expvar variables, ready to
+// be served on the /debug/vars endpoint. Every metric is published as an
+// expvar.Map keyed by it's joined labels, as "job_type=foo,pool=bar".
+// Histograms are published as their observations count and sum.
+//
+// As expvar doesn't allow to unpublish variables, Expvar sinks created with the
+// same namespace share their metrics maps, and creating a metric resets it's
+// already published map.
+type Expvar struct {
+	// Namespace is prefixed to every metric name.
+	Namespace string
+
+	vars map[string]*expvar.Map
+
+	sync.Mutex
+}
+
+// NewExpvar creates an Expvar sink.
+//
+// - namespace: The namespace prefixed to every metric name, "thrall_" if it's
+// empty as the other sinks do by default.
+//
+// Returns the Expvar sink.
+func NewExpvar(namespace string) *Expvar {
+	if namespace == "" {
+		namespace = "thrall_"
+	}
+
+	return &Expvar{
+		Namespace: namespace,
+		vars:      make(map[string]*expvar.Map),
+	}
+}
+
+// NewGaugeVecs publishes N number of gauge metrics.
+//
+// - labels: Gauge's label names.
+// - names: Gauge's names.
+//
+// Returns an error if any gauge publication fails.
+func (e *Expvar) NewGaugeVecs(labels []string, names ...string) error {
+	return e.publish(names...)
+}
+
+// NewCounterVecs publishes N number of counter metrics.
+//
+// - labels: Counter's label names.
+// - names: Counter's names.
+//
+// Returns an error if any counter publication fails.
+func (e *Expvar) NewCounterVecs(labels []string, names ...string) error {
+	return e.publish(names...)
+}
+
+// NewHistogramVecs publishes N number of histogram metrics.
+//
+// - buckets: Unused as only the observations count and sum are published.
+// - labels: Histogram's label names.
+// - names: Histogram's names.
+//
+// Returns an error if any histogram publication fails.
+func (e *Expvar) NewHistogramVecs(buckets []float64, labels []string, names ...string) error {
+	return e.publish(names...)
+}
+
+// NewGaugeFunc publishes a gauge metric which value is read from fn.
+//
+// - name: GaugeFunc's name.
+// - labels: GaugeFunc's labels.
+// - fn: The func that returns the gauge's current value.
+//
+// Returns an error if the gauge func publication fails.
+func (e *Expvar) NewGaugeFunc(name string, labels map[string]string, fn func() float64) error {
+	return e.newFunc(name, labels, fn)
+}
+
+// NewCounterFunc publishes a counter metric which value is read from fn.
+//
+// - name: CounterFunc's name.
+// - labels: CounterFunc's labels.
+// - fn: The func that returns the counter's current value.
+//
+// Returns an error if the counter func publication fails.
+func (e *Expvar) NewCounterFunc(name string, labels map[string]string, fn func() float64) error {
+	return e.newFunc(name, labels, fn)
+}
+
+// IncWith increases the value of N number of metrics given by it's names.
+//
+// - labels: The labels of the metrics to increase.
+// - names: The metric names to increase.
+//
+// Returns nothing.
+func (e *Expvar) IncWith(labels map[string]string, names ...string) {
+	for _, name := range names {
+		if v := e.get(name); v != nil {
+			v.AddFloat(joinLabels(labels, "="), 1)
+		}
+	}
+}
+
+// DecWith decreases the value of N number of metrics given by it's names.
+//
+// - labels: The labels of the metrics to decrease.
+// - names: The metric names to decrease.
+//
+// Returns nothing.
+func (e *Expvar) DecWith(labels map[string]string, names ...string) {
+	for _, name := range names {
+		if v := e.get(name); v != nil {
+			v.AddFloat(joinLabels(labels, "="), -1)
+		}
+	}
+}
+
+// ObserveWith adds a single observation to the histogram metric given by it's
+// name.
+//
+// - labels: The labels of the metric to observe.
+// - name: The metric name to observe.
+// - value: The observed value.
+//
+// Returns nothing.
+func (e *Expvar) ObserveWith(labels map[string]string, name string, value float64) {
+	v := e.get(name)
+	if v == nil {
+		return
+	}
+
+	key := joinLabels(labels, "=")
+	v.AddFloat(key+"_count", 1)
+	v.AddFloat(key+"_sum", value)
+}
+
+// publish publishes an empty expvar.Map for every given name, the already
+// published maps are reset and reused as expvar doesn't allow to unpublish
+// variables.
+//
+// - names: The metric names to publish.
+//
+// Returns an error if any publication fails.
+func (e *Expvar) publish(names ...string) error {
+	e.Lock()
+	defer e.Unlock()
+
+	for _, name := range names {
+		if name == "" {
+			return errors.New("metric's name should not be empty")
+		}
+
+		if _, exists := e.vars[name]; exists {
+			return fmt.Errorf("metric '%s' already published", name)
+		}
+
+		fullName := e.Namespace + name
+		switch v := expvar.Get(fullName).(type) {
+		case nil:
+			e.vars[name] = expvar.NewMap(fullName)
+		case *expvar.Map:
+			e.vars[name] = v.Init()
+		default:
+			return fmt.Errorf("metric '%s' already published by other kind", name)
+		}
+	}
+
+	return nil
+}
+
+// newFunc publishes a func based metric, the func is set on the metric's
+// expvar.Map under the joined labels key.
+//
+// - name: Func's name.
+// - labels: Func's labels.
+// - fn: The func that returns the metric's current value.
+//
+// Returns an error if the func publication fails.
+func (e *Expvar) newFunc(name string, labels map[string]string, fn func() float64) error {
+	if e.get(name) == nil {
+		if err := e.publish(name); err != nil {
+			return err
+		}
+	}
+
+	e.get(name).Set(joinLabels(labels, "="), expvar.Func(func() interface{} {
+		return fn()
+	}))
+
+	return nil
+}
+
+// get returns the published expvar.Map for a metric.
+//
+// - name: The metric name.
+//
+// Returns the metric's expvar.Map, nil if the metric is not published.
+func (e *Expvar) get(name string) *expvar.Map {
+	e.Lock()
+	defer e.Unlock()
+
+	return e.vars[name]
+}
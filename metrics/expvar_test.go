@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpvar(t *testing.T) {
+	assert := assert.New(t)
+
+	e := NewExpvar("test_")
+	labels := map[string]string{"pool": "foo", "job_type": "bar"}
+
+	t.Run("when Expvar succeed publishing counters and gauges", func(t *testing.T) {
+		assert.Nil(e.NewCounterVecs(nil, "received"))
+		assert.Nil(e.NewGaugeVecs(nil, "enqueued"))
+
+		e.IncWith(labels, "received", "enqueued")
+		e.IncWith(labels, "received", "enqueued")
+		e.DecWith(labels, "enqueued")
+
+		received := expvar.Get("test_received").(*expvar.Map)
+		assert.Equal("2", received.Get("job_type=bar,pool=foo").String())
+
+		enqueued := expvar.Get("test_enqueued").(*expvar.Map)
+		assert.Equal("1", enqueued.Get("job_type=bar,pool=foo").String())
+	})
+
+	t.Run("when Expvar succeed publishing histograms", func(t *testing.T) {
+		assert.Nil(e.NewHistogramVecs(nil, nil, "run_seconds"))
+
+		e.ObserveWith(labels, "run_seconds", 0.5)
+		e.ObserveWith(labels, "run_seconds", 1)
+
+		run := expvar.Get("test_run_seconds").(*expvar.Map)
+		assert.Equal("2", run.Get("job_type=bar,pool=foo_count").String())
+		assert.Equal("1.5", run.Get("job_type=bar,pool=foo_sum").String())
+	})
+
+	t.Run("when Expvar succeed publishing funcs", func(t *testing.T) {
+		assert.Nil(e.NewGaugeFunc("in_use", map[string]string{"limiter": "max"},
+			func() float64 { return 3 }))
+
+		inUse := expvar.Get("test_in_use").(*expvar.Map)
+		assert.Equal("3", inUse.Get("limiter=max").String())
+	})
+
+	t.Run("when Expvar succeed resetting another sink's metrics", func(t *testing.T) {
+		other := NewExpvar("test_")
+		assert.Nil(other.NewCounterVecs(nil, "received"))
+
+		received := expvar.Get("test_received").(*expvar.Map)
+		assert.Nil(received.Get("job_type=bar,pool=foo"))
+	})
+
+	t.Run("when Expvar succeed defaulting to the thrall namespace", func(t *testing.T) {
+		defaulted := NewExpvar("")
+		assert.Equal("thrall_", defaulted.Namespace)
+
+		assert.Nil(defaulted.NewCounterVecs(nil, "expvar_defaulted"))
+		assert.NotNil(expvar.Get("thrall_expvar_defaulted"))
+	})
+
+	t.Run("when Expvar fails publishing a metric twice", func(t *testing.T) {
+		err := e.NewCounterVecs(nil, "received")
+		assert.Equal("metric 'received' already published", err.Error())
+	})
+}
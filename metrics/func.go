@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewGaugeFunc creates a new Gauge metric which value is collected by calling
+// the given fn on every scrape.
+//
+// - name: GaugeFunc's name.
+// - labels: GaugeFunc's constant labels.
+// - fn: The func that returns the gauge's current value.
+//
+// Returns an error if the gauge func creation fails.
+func (r *Registry) NewGaugeFunc(name string, labels map[string]string, fn func() float64) error {
+	return r.newFunc(name, prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace:   r.Namespace,
+			Name:        name,
+			Help:        name,
+			ConstLabels: labels,
+		},
+		fn,
+	))
+}
+
+// NewCounterFunc creates a new Counter metric which value is collected by
+// calling the given fn on every scrape, fn should never return a lower value
+// than the previously returned one.
+//
+// - name: CounterFunc's name.
+// - labels: CounterFunc's constant labels.
+// - fn: The func that returns the counter's current value.
+//
+// Returns an error if the counter func creation fails.
+func (r *Registry) NewCounterFunc(name string, labels map[string]string, fn func() float64) error {
+	return r.newFunc(name, prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace:   r.Namespace,
+			Name:        name,
+			Help:        name,
+			ConstLabels: labels,
+		},
+		fn,
+	))
+}
+
+// newFunc adds and registers any func based metric collector.
+//
+// - name: Func's name.
+// - collector: The prometheus collector to add.
+//
+// Returns an error if the func creation fails.
+func (r *Registry) newFunc(name string, collector prometheus.Collector) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if name == "" {
+		return errors.New("func's name should not be empty")
+	}
+
+	if _, exists := r.Funcs[name]; exists {
+		return fmt.Errorf("func '%s' already registered", name)
+	}
+
+	if err := r.replace(collector); err != nil {
+		return fmt.Errorf("func '%s' not registered. Err: %v", name, err)
+	}
+
+	r.Funcs[name] = collector
+
+	return nil
+}
+
+// replace registers the given func collector on the Registry's Registerer,
+// unlike register an already registered equal collector is unregistered and
+// replaced, as it would keep calling the previous Registry's stale fn.
+//
+// - collector: The prometheus func collector to register.
+//
+// Returns an error if the registration fails.
+func (r *Registry) replace(collector prometheus.Collector) error {
+	err := r.registerer().Register(collector)
+
+	are, ok := err.(prometheus.AlreadyRegisteredError)
+	if !ok {
+		return err
+	}
+
+	if !r.unregister(are.ExistingCollector) {
+		return err
+	}
+
+	return r.registerer().Register(collector)
+}
+
+// CloseFunc unregister and remove an already created func metric.
+//
+// - name: Func's name to close.
+//
+// Returns an error if the func is not found.
+func (r *Registry) CloseFunc(name string) error {
+	if _, exists := r.Funcs[name]; !exists {
+		return fmt.Errorf("func '%s' not registered", name)
+	}
+
+	if unregistered := r.unregister(r.Funcs[name]); !unregistered {
+		return fmt.Errorf("func '%s' not unregistered", name)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.Funcs, name)
+
+	return nil
+}
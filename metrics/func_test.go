@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFuncs(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when NewGaugeFunc succeed creating a gauge func", func(t *testing.T) {
+		r := &Registry{
+			Funcs: make(map[string]prometheus.Collector),
+		}
+
+		err := r.NewGaugeFunc("foo_func", map[string]string{"limiter": "max"},
+			func() float64 { return 1 })
+
+		assert.Nil(err)
+		assert.Contains(r.Funcs, "foo_func")
+
+		err = r.CloseFunc("foo_func")
+		assert.Nil(err)
+		assert.Empty(r.Funcs)
+	})
+
+	t.Run("when NewCounterFunc succeed creating a counter func", func(t *testing.T) {
+		r := &Registry{
+			Funcs: make(map[string]prometheus.Collector),
+		}
+
+		err := r.NewCounterFunc("bar_func", nil, func() float64 { return 1 })
+
+		assert.Nil(err)
+		assert.Contains(r.Funcs, "bar_func")
+
+		err = r.CloseFunc("bar_func")
+		assert.Nil(err)
+		assert.Empty(r.Funcs)
+	})
+
+	t.Run("when NewGaugeFunc succeed replacing another Registry's gauge func", func(t *testing.T) {
+		registerer := prometheus.NewRegistry()
+
+		first := NewRegistry(WithRegisterer(registerer))
+		err := first.NewGaugeFunc("qux_func", nil, func() float64 { return 1 })
+		assert.Nil(err)
+
+		second := NewRegistry(WithRegisterer(registerer))
+		err = second.NewGaugeFunc("qux_func", nil, func() float64 { return 5 })
+		assert.Nil(err)
+
+		families, err := registerer.Gather()
+		assert.Nil(err)
+		assert.Len(families, 1)
+		assert.Equal(5.0, families[0].GetMetric()[0].GetGauge().GetValue())
+	})
+
+	t.Run("when NewGaugeFunc fails creating a gauge func", func(t *testing.T) {
+		t.Run("due empty name", func(t *testing.T) {
+			r := &Registry{
+				Funcs: make(map[string]prometheus.Collector),
+			}
+
+			err := r.NewGaugeFunc("", nil, func() float64 { return 1 })
+
+			assert.Equal("func's name should not be empty", err.Error())
+			assert.Empty(r.Funcs)
+		})
+
+		t.Run("due re-creating the gauge func twice", func(t *testing.T) {
+			r := &Registry{
+				Funcs: make(map[string]prometheus.Collector),
+			}
+
+			err := r.NewGaugeFunc("baz_func", nil, func() float64 { return 1 })
+			assert.Nil(err)
+
+			err = r.NewGaugeFunc("baz_func", nil, func() float64 { return 1 })
+			assert.Equal("func 'baz_func' already registered", err.Error())
+			assert.Len(r.Funcs, 1)
+
+			err = r.CloseFunc("baz_func")
+			assert.Nil(err)
+		})
+	})
+}
@@ -25,14 +25,18 @@ func (r *Registry) NewGauges(names ...string) error {
 			return fmt.Errorf("gauge '%s' already registered", name)
 		}
 
-		r.Gauges[name] = prometheus.NewGauge(
+		collector, err := r.register(prometheus.NewGauge(
 			prometheus.GaugeOpts{
-				Name: name,
-				Help: name,
+				Namespace: r.Namespace,
+				Name:      name,
+				Help:      name,
 			},
-		)
+		))
+		if err != nil {
+			return fmt.Errorf("gauge '%s' not registered. Err: %v", name, err)
+		}
 
-		prometheus.Register(r.Gauges[name])
+		r.Gauges[name] = collector.(prometheus.Gauge)
 	}
 
 	return nil
@@ -48,7 +52,7 @@ func (r *Registry) CloseGauge(name string) error {
 		return fmt.Errorf("gauge '%s' not registered", name)
 	}
 
-	if unregistered := prometheus.Unregister(r.Gauges[name]); !unregistered {
+	if unregistered := r.unregister(r.Gauges[name]); !unregistered {
 		return fmt.Errorf("gauge '%s' not unregistered", name)
 	}
 
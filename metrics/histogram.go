@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewHistograms creates N number of new Histogram metrics sharing the same
+// buckets.
+//
+// - buckets: Histogram's upper bounds, prometheus.DefBuckets is used if empty.
+// - names: Histogram's names.
+//
+// Returns an error if any histogram creation fails.
+func (r *Registry) NewHistograms(buckets []float64, names ...string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	for _, name := range names {
+		if name == "" {
+			return errors.New("histogram's name should not be empty")
+		}
+
+		if _, exists := r.Histograms[name]; exists {
+			return fmt.Errorf("histogram '%s' already registered", name)
+		}
+
+		collector, err := r.register(prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: r.Namespace,
+				Name:      name,
+				Help:      name,
+				Buckets:   buckets,
+			},
+		))
+		if err != nil {
+			return fmt.Errorf("histogram '%s' not registered. Err: %v", name, err)
+		}
+
+		r.Histograms[name] = collector.(prometheus.Histogram)
+	}
+
+	return nil
+}
+
+// CloseHistogram unregister and remove an already created Histogram.
+//
+// - name: Histogram's name to close.
+//
+// Returns an error if the histogram is not found.
+func (r *Registry) CloseHistogram(name string) error {
+	if _, exists := r.Histograms[name]; !exists {
+		return fmt.Errorf("histogram '%s' not registered", name)
+	}
+
+	if unregistered := r.unregister(r.Histograms[name]); !unregistered {
+		return fmt.Errorf("histogram '%s' not unregistered", name)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.Histograms, name)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHistograms(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when NewHistograms succeed creating a histogram", func(t *testing.T) {
+		r := &Registry{
+			Histograms: make(map[string]prometheus.Histogram),
+		}
+
+		err := r.NewHistograms(nil, "foo_histogram")
+
+		assert.Nil(err)
+		assert.Equal(len(r.Histograms), 1)
+		assert.Contains(r.Histograms, "foo_histogram")
+
+		r.Observe("foo_histogram", 0.5)
+
+		err = r.CloseHistogram("foo_histogram")
+		assert.Nil(err)
+		assert.Equal(len(r.Histograms), 0)
+	})
+
+	t.Run("when NewHistograms fails creating a histogram", func(t *testing.T) {
+		t.Run("due empty name", func(t *testing.T) {
+			r := &Registry{
+				Histograms: make(map[string]prometheus.Histogram),
+			}
+
+			err := r.NewHistograms(nil, "")
+
+			assert.Equal("histogram's name should not be empty", err.Error())
+			assert.Empty(r.Histograms)
+		})
+
+		t.Run("due re-creating the histogram twice", func(t *testing.T) {
+			r := &Registry{
+				Histograms: make(map[string]prometheus.Histogram),
+			}
+
+			err := r.NewHistograms(nil, "foo_histogram")
+
+			assert.Nil(err)
+
+			err = r.NewHistograms(nil, "foo_histogram")
+
+			assert.Equal("histogram 'foo_histogram' already registered", err.Error())
+			assert.Equal(len(r.Histograms), 1)
+			assert.Contains(r.Histograms, "foo_histogram")
+
+			err = r.CloseHistogram("foo_histogram")
+			assert.Nil(err)
+			assert.Equal(len(r.Histograms), 0)
+		})
+	})
+}
+
+func TestCloseHistogram(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when CloseHistogram fails on closing a histogram because it doesn't exists", func(t *testing.T) {
+		r := &Registry{
+			Histograms: make(map[string]prometheus.Histogram),
+		}
+
+		err := r.CloseHistogram("foo_histogram")
+		assert.Equal("histogram 'foo_histogram' not registered", err.Error())
+		assert.Equal(len(r.Histograms), 0)
+	})
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"net/http"
+	"reflect"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,22 +13,155 @@ import (
 // package you should first create a Registry, then start adding metrics to the
 // Registry, then modify those metrics values.
 type Registry struct {
-	Gauges   map[string]prometheus.Gauge
-	Counters map[string]prometheus.Counter
+	Gauges     map[string]prometheus.Gauge
+	Counters   map[string]prometheus.Counter
+	Histograms map[string]prometheus.Histogram
+	Summaries  map[string]prometheus.Summary
+	Funcs      map[string]prometheus.Collector
+
+	GaugeVecs     map[string]*prometheus.GaugeVec
+	CounterVecs   map[string]*prometheus.CounterVec
+	HistogramVecs map[string]*prometheus.HistogramVec
+
+	// Namespace is prefixed to every metric name created on the Registry, It
+	// defaults to "thrall" as the metrics reported by thrall.
+	Namespace string
+
+	// Registerer is where the Registry metrics are registered, and Gatherer is
+	// where they are gathered from to be exposed by the Handler. Both default
+	// to the prometheus global registry.
+	Registerer prometheus.Registerer
+	Gatherer   prometheus.Gatherer
 
 	sync.Mutex
 }
 
-// NewRegistry creates an empty registry and configures the metrics endpoint.
+// NewRegistry creates an empty registry, the metrics endpoint is not
+// configured, check the Registry's Handler func to expose the metrics.
+//
+// - opts: function option initializers, check the following With.. funcs.
 //
 // Returns an empty Registry.
-func NewRegistry() *Registry {
-	http.Handle("/metrics", promhttp.Handler())
+func NewRegistry(opts ...func(*Registry)) *Registry {
+	r := &Registry{
+		Gauges:     make(map[string]prometheus.Gauge),
+		Counters:   make(map[string]prometheus.Counter),
+		Histograms: make(map[string]prometheus.Histogram),
+		Summaries:  make(map[string]prometheus.Summary),
+		Funcs:      make(map[string]prometheus.Collector),
+
+		GaugeVecs:     make(map[string]*prometheus.GaugeVec),
+		CounterVecs:   make(map[string]*prometheus.CounterVec),
+		HistogramVecs: make(map[string]*prometheus.HistogramVec),
+
+		Namespace:  "thrall",
+		Registerer: prometheus.DefaultRegisterer,
+		Gatherer:   prometheus.DefaultGatherer,
+	}
+
+	for _, option := range opts {
+		option(r)
+	}
+
+	return r
+}
+
+// WithRegisterer is an optional func for NewRegistry, It does configure the
+// prometheus Registerer where the metrics would be registered. If the given
+// Registerer is also a Gatherer it's used to gather the metrics too.
+//
+// - registerer: The prometheus Registerer, as a prometheus.NewRegistry().
+//
+// Returns a optional configuration function.
+func WithRegisterer(registerer prometheus.Registerer) func(*Registry) {
+	return func(r *Registry) {
+		r.Registerer = registerer
+
+		if gatherer, ok := registerer.(prometheus.Gatherer); ok {
+			r.Gatherer = gatherer
+		}
+	}
+}
+
+// WithGatherer is an optional func for NewRegistry, It does configure the
+// prometheus Gatherer that the Handler would expose.
+//
+// - gatherer: The prometheus Gatherer.
+//
+// Returns a optional configuration function.
+func WithGatherer(gatherer prometheus.Gatherer) func(*Registry) {
+	return func(r *Registry) {
+		r.Gatherer = gatherer
+	}
+}
+
+// WithNamespace is an optional func for NewRegistry, It does configure a
+// namespace that would be prefixed to every metric name, as "myapp" for
+// "myapp_workerpool_job_received" instead of the default "thrall" one.
+//
+// - namespace: The metrics namespace, empty to not prefix the metric names.
+//
+// Returns a optional configuration function.
+func WithNamespace(namespace string) func(*Registry) {
+	return func(r *Registry) {
+		r.Namespace = namespace
+	}
+}
+
+// Handler returns an http.Handler that exposes the Registry's gathered
+// metrics, ready to be mounted on any http server to be scrapped.
+//
+// Returns the metrics http.Handler.
+func (r *Registry) Handler() http.Handler {
+	gatherer := r.Gatherer
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// register registers the given collector on the Registry's Registerer, if an
+// equal collector was already registered, as it happens when creating two
+// Registries on the same Registerer, the already registered one is returned.
+//
+// - collector: The prometheus collector to register.
+//
+// Returns the registered collector or an error if the registration fails.
+func (r *Registry) register(collector prometheus.Collector) (prometheus.Collector, error) {
+	err := r.registerer().Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		if reflect.TypeOf(are.ExistingCollector) == reflect.TypeOf(collector) {
+			return are.ExistingCollector, nil
+		}
+	}
+
+	return nil, err
+}
+
+// unregister unregisters the given collector from the Registry's Registerer.
+//
+// - collector: The prometheus collector to unregister.
+//
+// Returns true if the collector was unregistered.
+func (r *Registry) unregister(collector prometheus.Collector) bool {
+	return r.registerer().Unregister(collector)
+}
 
-	return &Registry{
-		Gauges:   make(map[string]prometheus.Gauge),
-		Counters: make(map[string]prometheus.Counter),
+// registerer returns the Registry's Registerer, as the Registry may have been
+// created without NewRegistry it defaults to the prometheus global registry.
+//
+// Returns the Registry's Registerer.
+func (r *Registry) registerer() prometheus.Registerer {
+	if r.Registerer == nil {
+		return prometheus.DefaultRegisterer
 	}
+
+	return r.Registerer
 }
 
 // Inc increases the value of N number of metrics given by it's names.
@@ -59,3 +193,68 @@ func (r *Registry) Dec(names ...string) {
 		}
 	}
 }
+
+// Observe adds a single observation to the histogram and summary metrics given
+// by it's name.
+//
+// - name: The metric name to observe.
+// - value: The observed value.
+//
+// Returns nothing.
+func (r *Registry) Observe(name string, value float64) {
+	if histogram, exists := r.Histograms[name]; exists {
+		histogram.Observe(value)
+	}
+
+	if summary, exists := r.Summaries[name]; exists {
+		summary.Observe(value)
+	}
+}
+
+// IncWith increases the value of N number of labeled metrics given by it's
+// names.
+//
+// - labels: The label values of the metrics to increase.
+// - names: The metric names to increase.
+//
+// Returns nothing.
+func (r *Registry) IncWith(labels map[string]string, names ...string) {
+	for _, name := range names {
+		if vec, exists := r.GaugeVecs[name]; exists {
+			vec.With(labels).Inc()
+		}
+
+		if vec, exists := r.CounterVecs[name]; exists {
+			vec.With(labels).Inc()
+		}
+	}
+}
+
+// DecWith decreases the value of N number of labeled metrics given by it's
+// names.
+//
+// - labels: The label values of the metrics to decrease.
+// - names: The metric names to decrease.
+//
+// Returns nothing.
+func (r *Registry) DecWith(labels map[string]string, names ...string) {
+	for _, name := range names {
+		if vec, exists := r.GaugeVecs[name]; exists {
+			vec.With(labels).Dec()
+		}
+	}
+}
+
+// ObserveWith adds a single observation to the labeled histogram metric given
+// by it's name.
+//
+// - labels: The label values of the metric to observe.
+// - name: The metric name to observe.
+// - value: The observed value.
+//
+// Returns nothing.
+func (r *Registry) ObserveWith(labels map[string]string, name string, value float64) {
+	if vec, exists := r.HistogramVecs[name]; exists {
+		vec.With(labels).Observe(value)
+	}
+}
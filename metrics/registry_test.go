@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,5 +18,42 @@ func TestNewRegistry(t *testing.T) {
 		assert.NotNil(r)
 		assert.NotNil(r.Gauges)
 		assert.NotNil(r.Counters)
+		assert.NotNil(r.Histograms)
+		assert.NotNil(r.Summaries)
+		assert.NotNil(r.Funcs)
+		assert.NotNil(r.GaugeVecs)
+		assert.NotNil(r.CounterVecs)
+		assert.NotNil(r.HistogramVecs)
+		assert.Equal("thrall", r.Namespace)
+	})
+}
+
+func TestRegistryHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Handler succeed exposing the registerer metrics", func(t *testing.T) {
+		registerer := prometheus.NewRegistry()
+
+		r := NewRegistry(WithRegisterer(registerer), WithNamespace("foo"))
+
+		assert.Nil(r.NewCounters("bar"))
+		r.Inc("bar")
+
+		recorder := httptest.NewRecorder()
+		r.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(http.StatusOK, recorder.Code)
+		assert.Contains(recorder.Body.String(), "foo_bar 1")
+	})
+
+	t.Run("when the same metrics are registered twice on a registerer", func(t *testing.T) {
+		registerer := prometheus.NewRegistry()
+
+		first := NewRegistry(WithRegisterer(registerer))
+		second := NewRegistry(WithRegisterer(registerer))
+
+		assert.Nil(first.NewCounters("bar"))
+		assert.Nil(second.NewCounters("bar"))
+		assert.Equal(first.Counters["bar"], second.Counters["bar"])
 	})
 }
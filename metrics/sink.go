@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sink is the metrics backend that thrall reports into. The metrics are first
+// declared with their kind and label names, then their values are modified by
+// their names. Registry is the prometheus Sink, check also StatsD, Expvar and
+// Nop.
+type Sink interface {
+	NewGaugeVecs(labels []string, names ...string) error
+	NewCounterVecs(labels []string, names ...string) error
+	NewHistogramVecs(buckets []float64, labels []string, names ...string) error
+	NewGaugeFunc(name string, labels map[string]string, fn func() float64) error
+	NewCounterFunc(name string, labels map[string]string, fn func() float64) error
+
+	IncWith(labels map[string]string, names ...string)
+	DecWith(labels map[string]string, names ...string)
+	ObserveWith(labels map[string]string, name string, value float64)
+}
+
+// Nop is a Sink that discards every metric, It's the default Sink when no
+// metrics are configured.
+type Nop struct{}
+
+// NewGaugeVecs does nothing but it's necesary to implement the Sink interface.
+func (Nop) NewGaugeVecs(labels []string, names ...string) error { return nil }
+
+// NewCounterVecs does nothing but it's necesary to implement the Sink interface.
+func (Nop) NewCounterVecs(labels []string, names ...string) error { return nil }
+
+// NewHistogramVecs does nothing but it's necesary to implement the Sink
+// interface.
+func (Nop) NewHistogramVecs(buckets []float64, labels []string, names ...string) error {
+	return nil
+}
+
+// NewGaugeFunc does nothing but it's necesary to implement the Sink interface.
+func (Nop) NewGaugeFunc(name string, labels map[string]string, fn func() float64) error {
+	return nil
+}
+
+// NewCounterFunc does nothing but it's necesary to implement the Sink
+// interface.
+func (Nop) NewCounterFunc(name string, labels map[string]string, fn func() float64) error {
+	return nil
+}
+
+// IncWith does nothing but it's necesary to implement the Sink interface.
+func (Nop) IncWith(labels map[string]string, names ...string) {}
+
+// DecWith does nothing but it's necesary to implement the Sink interface.
+func (Nop) DecWith(labels map[string]string, names ...string) {}
+
+// ObserveWith does nothing but it's necesary to implement the Sink interface.
+func (Nop) ObserveWith(labels map[string]string, name string, value float64) {}
+
+// joinLabels joins the given labels sorted by their names, as
+// "job_type:foo,pool:bar", to be used by the Sinks without labels support.
+//
+// - labels: The labels to join.
+// - sep: The separator between a label name and it's value.
+//
+// Returns the joined labels.
+func joinLabels(labels map[string]string, sep string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+sep+labels[key])
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,326 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// statsdFunc is a func based metric reported by the StatsD sink on every
+// flush.
+type statsdFunc struct {
+	name    string
+	labels  map[string]string
+	fn      func() float64
+	counter bool
+	last    float64
+}
+
+// StatsD is a Sink that reports the metrics over UDP using the StatsD line
+// protocol, the labels are sent as DogStatsD tags. Counters are sent as "c",
+// gauges as "g" deltas and histograms as "h" samples, func based metrics are
+// read and sent on every FlushInterval.
+type StatsD struct {
+	// Namespace is prefixed to every metric name, It defaults to "thrall." for
+	// "thrall.workerpool_job_received".
+	Namespace string
+
+	// FlushInterval is how often the func based metrics are reported.
+	FlushInterval time.Duration
+
+	conn  net.Conn
+	kinds map[string]string
+	funcs []*statsdFunc
+	close chan bool
+
+	sync.Mutex
+}
+
+// NewStatsD creates a StatsD sink that reports to the given address.
+//
+// - addr: The StatsD server UDP address, as "127.0.0.1:8125".
+// - opts: function option initializers, check the following WithStatsD.. funcs.
+//
+// Returns the StatsD sink or an error if the address can't be dialed.
+func NewStatsD(addr string, opts ...func(*StatsD)) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd '%s' not dialed. Err: %v", addr, err)
+	}
+
+	s := &StatsD{
+		Namespace:     "thrall.",
+		FlushInterval: 10 * time.Second,
+		conn:          conn,
+		kinds:         make(map[string]string),
+		close:         make(chan bool),
+	}
+
+	for _, option := range opts {
+		option(s)
+	}
+
+	go s.run()
+
+	return s, nil
+}
+
+// WithStatsDNamespace is an optional func for NewStatsD, It does configure a
+// namespace that would be prefixed to every metric name.
+//
+// - namespace: The metrics namespace, as "myapp.".
+//
+// Returns a optional configuration function.
+func WithStatsDNamespace(namespace string) func(*StatsD) {
+	return func(s *StatsD) {
+		s.Namespace = namespace
+	}
+}
+
+// WithStatsDFlushInterval is an optional func for NewStatsD, It does configure
+// how often the func based metrics are reported.
+//
+// - interval: The flush interval.
+//
+// Returns a optional configuration function.
+func WithStatsDFlushInterval(interval time.Duration) func(*StatsD) {
+	return func(s *StatsD) {
+		s.FlushInterval = interval
+	}
+}
+
+// NewGaugeVecs declares N number of gauge metrics.
+//
+// - labels: Gauge's label names, unused as StatsD tags are sent on every value.
+// - names: Gauge's names.
+//
+// Returns an error if any gauge declaration fails.
+func (s *StatsD) NewGaugeVecs(labels []string, names ...string) error {
+	return s.declare("g", names...)
+}
+
+// NewCounterVecs declares N number of counter metrics.
+//
+// - labels: Counter's label names, unused as StatsD tags are sent on every
+// value.
+// - names: Counter's names.
+//
+// Returns an error if any counter declaration fails.
+func (s *StatsD) NewCounterVecs(labels []string, names ...string) error {
+	return s.declare("c", names...)
+}
+
+// NewHistogramVecs declares N number of histogram metrics.
+//
+// - buckets: Unused as the StatsD server computes the histograms.
+// - labels: Histogram's label names, unused as StatsD tags are sent on every
+// value.
+// - names: Histogram's names.
+//
+// Returns an error if any histogram declaration fails.
+func (s *StatsD) NewHistogramVecs(buckets []float64, labels []string, names ...string) error {
+	return s.declare("h", names...)
+}
+
+// NewGaugeFunc creates a gauge metric which value is read from fn and sent on
+// every flush.
+//
+// - name: GaugeFunc's name.
+// - labels: GaugeFunc's tags.
+// - fn: The func that returns the gauge's current value.
+//
+// Returns an error if the gauge func creation fails.
+func (s *StatsD) NewGaugeFunc(name string, labels map[string]string, fn func() float64) error {
+	return s.newFunc(&statsdFunc{name: name, labels: labels, fn: fn})
+}
+
+// NewCounterFunc creates a counter metric which value is read from fn on
+// every flush, only the increment since the previous flush is sent.
+//
+// - name: CounterFunc's name.
+// - labels: CounterFunc's tags.
+// - fn: The func that returns the counter's current value.
+//
+// Returns an error if the counter func creation fails.
+func (s *StatsD) NewCounterFunc(name string, labels map[string]string, fn func() float64) error {
+	return s.newFunc(&statsdFunc{name: name, labels: labels, fn: fn, counter: true})
+}
+
+// IncWith increases the value of N number of metrics given by it's names.
+//
+// - labels: The tags of the metrics to increase.
+// - names: The metric names to increase.
+//
+// Returns nothing.
+func (s *StatsD) IncWith(labels map[string]string, names ...string) {
+	for _, name := range names {
+		switch s.kind(name) {
+		case "c":
+			s.send(name, "1", "c", labels)
+		case "g":
+			s.send(name, "+1", "g", labels)
+		}
+	}
+}
+
+// DecWith decreases the value of N number of gauge metrics given by it's
+// names.
+//
+// - labels: The tags of the metrics to decrease.
+// - names: The metric names to decrease.
+//
+// Returns nothing.
+func (s *StatsD) DecWith(labels map[string]string, names ...string) {
+	for _, name := range names {
+		if s.kind(name) == "g" {
+			s.send(name, "-1", "g", labels)
+		}
+	}
+}
+
+// ObserveWith sends a single histogram sample for the metric given by it's
+// name.
+//
+// - labels: The tags of the metric to observe.
+// - name: The metric name to observe.
+// - value: The observed value.
+//
+// Returns nothing.
+func (s *StatsD) ObserveWith(labels map[string]string, name string, value float64) {
+	if s.kind(name) == "h" {
+		s.send(name, formatFloat(value), "h", labels)
+	}
+}
+
+// Close stops the StatsD flushes and closes it's connection.
+//
+// Returns an error if the connection can't be closed.
+func (s *StatsD) Close() error {
+	close(s.close)
+	return s.conn.Close()
+}
+
+// declare sets the StatsD type for the given metric names.
+//
+// - kind: The StatsD metric type.
+// - names: The metric names to declare.
+//
+// Returns an error if any metric declaration fails.
+func (s *StatsD) declare(kind string, names ...string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, name := range names {
+		if name == "" {
+			return errors.New("metric's name should not be empty")
+		}
+
+		if _, exists := s.kinds[name]; exists {
+			return fmt.Errorf("metric '%s' already declared", name)
+		}
+
+		s.kinds[name] = kind
+	}
+
+	return nil
+}
+
+// newFunc adds a func based metric to be sent on every flush.
+//
+// - f: The func based metric.
+//
+// Returns an error if the func creation fails.
+func (s *StatsD) newFunc(f *statsdFunc) error {
+	if f.name == "" {
+		return errors.New("func's name should not be empty")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.funcs = append(s.funcs, f)
+
+	return nil
+}
+
+// kind returns the declared StatsD type for a metric.
+//
+// - name: The metric name.
+//
+// Returns the StatsD type, empty if the metric is not declared.
+func (s *StatsD) kind(name string) string {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.kinds[name]
+}
+
+// run flushes the func based metrics on every FlushInterval until closed.
+//
+// Returns nothing.
+func (s *StatsD) run() {
+	ticker := time.NewTicker(s.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.close:
+			return
+		}
+	}
+}
+
+// flush sends the func based metrics current values.
+//
+// Returns nothing.
+func (s *StatsD) flush() {
+	s.Lock()
+	funcs := s.funcs
+	s.Unlock()
+
+	for _, f := range funcs {
+		value := f.fn()
+
+		if !f.counter {
+			s.send(f.name, formatFloat(value), "g", f.labels)
+			continue
+		}
+
+		if delta := value - f.last; delta > 0 {
+			s.send(f.name, formatFloat(delta), "c", f.labels)
+		}
+		f.last = value
+	}
+}
+
+// send writes a single StatsD line, the errors are ignored as StatsD metrics
+// are sent on a best effort basis.
+//
+// - name: The metric name.
+// - value: The metric value.
+// - kind: The StatsD metric type.
+// - labels: The metric tags.
+//
+// Returns nothing.
+func (s *StatsD) send(name, value, kind string, labels map[string]string) {
+	line := s.Namespace + name + ":" + value + "|" + kind
+	if len(labels) > 0 {
+		line += "|#" + joinLabels(labels, ":")
+	}
+
+	s.conn.Write([]byte(line))
+}
+
+// formatFloat formats a metric value without trailing zeros.
+//
+// - value: The value to format.
+//
+// Returns the formatted value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
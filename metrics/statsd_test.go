@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readStatsD(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 1024)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Timeout waiting for a statsd packet. Err: %v", err)
+	}
+
+	return string(buf[:n])
+}
+
+func TestStatsD(t *testing.T) {
+	assert := assert.New(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(err)
+	defer conn.Close()
+
+	s, err := NewStatsD(conn.LocalAddr().String(),
+		WithStatsDNamespace("thrall."),
+		WithStatsDFlushInterval(10*time.Millisecond),
+	)
+	assert.Nil(err)
+	defer s.Close()
+
+	labels := map[string]string{"pool": "foo", "job_type": "bar"}
+
+	t.Run("when StatsD succeed sending a counter", func(t *testing.T) {
+		assert.Nil(s.NewCounterVecs(nil, "received"))
+
+		s.IncWith(labels, "received")
+		assert.Equal("thrall.received:1|c|#job_type:bar,pool:foo", readStatsD(t, conn))
+	})
+
+	t.Run("when StatsD succeed sending a gauge", func(t *testing.T) {
+		assert.Nil(s.NewGaugeVecs(nil, "enqueued"))
+
+		s.IncWith(labels, "enqueued")
+		assert.Equal("thrall.enqueued:+1|g|#job_type:bar,pool:foo", readStatsD(t, conn))
+
+		s.DecWith(labels, "enqueued")
+		assert.Equal("thrall.enqueued:-1|g|#job_type:bar,pool:foo", readStatsD(t, conn))
+	})
+
+	t.Run("when StatsD succeed sending a histogram", func(t *testing.T) {
+		assert.Nil(s.NewHistogramVecs(nil, nil, "run_seconds"))
+
+		s.ObserveWith(labels, "run_seconds", 0.25)
+		assert.Equal("thrall.run_seconds:0.25|h|#job_type:bar,pool:foo", readStatsD(t, conn))
+	})
+
+	t.Run("when StatsD succeed flushing a gauge func", func(t *testing.T) {
+		assert.Nil(s.NewGaugeFunc("in_use", nil, func() float64 { return 3 }))
+
+		assert.Equal("thrall.in_use:3|g", readStatsD(t, conn))
+	})
+
+	t.Run("when StatsD fails declaring a metric twice", func(t *testing.T) {
+		err := s.NewCounterVecs(nil, "received")
+		assert.Equal("metric 'received' already declared", err.Error())
+	})
+}
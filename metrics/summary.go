@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewSummaries creates N number of new Summary metrics sharing the same
+// objectives.
+//
+// - objectives: Summary's quantiles and their absolute errors.
+// - names: Summary's names.
+//
+// Returns an error if any summary creation fails.
+func (r *Registry) NewSummaries(objectives map[float64]float64, names ...string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, name := range names {
+		if name == "" {
+			return errors.New("summary's name should not be empty")
+		}
+
+		if _, exists := r.Summaries[name]; exists {
+			return fmt.Errorf("summary '%s' already registered", name)
+		}
+
+		collector, err := r.register(prometheus.NewSummary(
+			prometheus.SummaryOpts{
+				Namespace:  r.Namespace,
+				Name:       name,
+				Help:       name,
+				Objectives: objectives,
+			},
+		))
+		if err != nil {
+			return fmt.Errorf("summary '%s' not registered. Err: %v", name, err)
+		}
+
+		r.Summaries[name] = collector.(prometheus.Summary)
+	}
+
+	return nil
+}
+
+// CloseSummary unregister and remove an already created Summary.
+//
+// - name: Summary's name to close.
+//
+// Returns an error if the summary is not found.
+func (r *Registry) CloseSummary(name string) error {
+	if _, exists := r.Summaries[name]; !exists {
+		return fmt.Errorf("summary '%s' not registered", name)
+	}
+
+	if unregistered := r.unregister(r.Summaries[name]); !unregistered {
+		return fmt.Errorf("summary '%s' not unregistered", name)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.Summaries, name)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSummaries(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when NewSummaries succeed creating a summary", func(t *testing.T) {
+		r := &Registry{
+			Summaries: make(map[string]prometheus.Summary),
+		}
+
+		err := r.NewSummaries(map[float64]float64{0.5: 0.05}, "foo_summary")
+
+		assert.Nil(err)
+		assert.Equal(len(r.Summaries), 1)
+		assert.Contains(r.Summaries, "foo_summary")
+
+		r.Observe("foo_summary", 0.5)
+
+		err = r.CloseSummary("foo_summary")
+		assert.Nil(err)
+		assert.Equal(len(r.Summaries), 0)
+	})
+
+	t.Run("when NewSummaries fails creating a summary", func(t *testing.T) {
+		t.Run("due empty name", func(t *testing.T) {
+			r := &Registry{
+				Summaries: make(map[string]prometheus.Summary),
+			}
+
+			err := r.NewSummaries(map[float64]float64{0.5: 0.05}, "")
+
+			assert.Equal("summary's name should not be empty", err.Error())
+			assert.Empty(r.Summaries)
+		})
+
+		t.Run("due re-creating the summary twice", func(t *testing.T) {
+			r := &Registry{
+				Summaries: make(map[string]prometheus.Summary),
+			}
+
+			err := r.NewSummaries(map[float64]float64{0.5: 0.05}, "foo_summary")
+
+			assert.Nil(err)
+
+			err = r.NewSummaries(map[float64]float64{0.5: 0.05}, "foo_summary")
+
+			assert.Equal("summary 'foo_summary' already registered", err.Error())
+			assert.Equal(len(r.Summaries), 1)
+			assert.Contains(r.Summaries, "foo_summary")
+
+			err = r.CloseSummary("foo_summary")
+			assert.Nil(err)
+			assert.Equal(len(r.Summaries), 0)
+		})
+	})
+}
+
+func TestCloseSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when CloseSummary fails on closing a summary because it doesn't exists", func(t *testing.T) {
+		r := &Registry{
+			Summaries: make(map[string]prometheus.Summary),
+		}
+
+		err := r.CloseSummary("foo_summary")
+		assert.Equal("summary 'foo_summary' not registered", err.Error())
+		assert.Equal(len(r.Summaries), 0)
+	})
+}
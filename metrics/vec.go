@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewGaugeVecs creates N number of new labeled Gauge metrics.
+//
+// - labels: GaugeVec's label names.
+// - names: GaugeVec's names.
+//
+// Returns an error if any gauge vec creation fails.
+func (r *Registry) NewGaugeVecs(labels []string, names ...string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, name := range names {
+		if err := r.checkVec(name); err != nil {
+			return err
+		}
+
+		collector, err := r.register(prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: r.Namespace,
+				Name:      name,
+				Help:      name,
+			},
+			labels,
+		))
+		if err != nil {
+			return fmt.Errorf("vec '%s' not registered. Err: %v", name, err)
+		}
+
+		r.GaugeVecs[name] = collector.(*prometheus.GaugeVec)
+	}
+
+	return nil
+}
+
+// NewCounterVecs creates N number of new labeled Counter metrics.
+//
+// - labels: CounterVec's label names.
+// - names: CounterVec's names.
+//
+// Returns an error if any counter vec creation fails.
+func (r *Registry) NewCounterVecs(labels []string, names ...string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, name := range names {
+		if err := r.checkVec(name); err != nil {
+			return err
+		}
+
+		collector, err := r.register(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: r.Namespace,
+				Name:      name,
+				Help:      name,
+			},
+			labels,
+		))
+		if err != nil {
+			return fmt.Errorf("vec '%s' not registered. Err: %v", name, err)
+		}
+
+		r.CounterVecs[name] = collector.(*prometheus.CounterVec)
+	}
+
+	return nil
+}
+
+// NewHistogramVecs creates N number of new labeled Histogram metrics sharing
+// the same buckets.
+//
+// - buckets: HistogramVec's upper bounds, prometheus.DefBuckets if empty.
+// - labels: HistogramVec's label names.
+// - names: HistogramVec's names.
+//
+// Returns an error if any histogram vec creation fails.
+func (r *Registry) NewHistogramVecs(buckets []float64, labels []string, names ...string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	for _, name := range names {
+		if err := r.checkVec(name); err != nil {
+			return err
+		}
+
+		collector, err := r.register(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: r.Namespace,
+				Name:      name,
+				Help:      name,
+				Buckets:   buckets,
+			},
+			labels,
+		))
+		if err != nil {
+			return fmt.Errorf("vec '%s' not registered. Err: %v", name, err)
+		}
+
+		r.HistogramVecs[name] = collector.(*prometheus.HistogramVec)
+	}
+
+	return nil
+}
+
+// CloseVec unregister and remove an already created labeled metric.
+//
+// - name: Vec's name to close.
+//
+// Returns an error if the vec is not found.
+func (r *Registry) CloseVec(name string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	var collector prometheus.Collector
+	if vec, exists := r.GaugeVecs[name]; exists {
+		collector = vec
+	} else if vec, exists := r.CounterVecs[name]; exists {
+		collector = vec
+	} else if vec, exists := r.HistogramVecs[name]; exists {
+		collector = vec
+	} else {
+		return fmt.Errorf("vec '%s' not registered", name)
+	}
+
+	if unregistered := r.unregister(collector); !unregistered {
+		return fmt.Errorf("vec '%s' not unregistered", name)
+	}
+
+	delete(r.GaugeVecs, name)
+	delete(r.CounterVecs, name)
+	delete(r.HistogramVecs, name)
+
+	return nil
+}
+
+// checkVec validates a vec name, It should not be empty neither already used
+// by any other vec.
+//
+// - name: Vec's name to check.
+//
+// Returns an error if the name is not valid.
+func (r *Registry) checkVec(name string) error {
+	if name == "" {
+		return errors.New("vec's name should not be empty")
+	}
+
+	_, gauge := r.GaugeVecs[name]
+	_, counter := r.CounterVecs[name]
+	_, histogram := r.HistogramVecs[name]
+	if gauge || counter || histogram {
+		return fmt.Errorf("vec '%s' already registered", name)
+	}
+
+	return nil
+}
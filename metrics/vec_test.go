@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVecRegistry() *Registry {
+	return &Registry{
+		GaugeVecs:     make(map[string]*prometheus.GaugeVec),
+		CounterVecs:   make(map[string]*prometheus.CounterVec),
+		HistogramVecs: make(map[string]*prometheus.HistogramVec),
+	}
+}
+
+func TestNewVecs(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when NewVecs succeed creating labeled metrics", func(t *testing.T) {
+		r := newVecRegistry()
+
+		assert.Nil(r.NewGaugeVecs([]string{"job_type"}, "foo_gauge_vec"))
+		assert.Nil(r.NewCounterVecs([]string{"job_type"}, "foo_counter_vec"))
+		assert.Nil(r.NewHistogramVecs(nil, []string{"job_type"}, "foo_histogram_vec"))
+
+		labels := map[string]string{"job_type": "foo"}
+		r.IncWith(labels, "foo_gauge_vec", "foo_counter_vec")
+		r.IncWith(labels, "foo_gauge_vec", "foo_counter_vec")
+		r.DecWith(labels, "foo_gauge_vec", "foo_counter_vec")
+		r.ObserveWith(labels, "foo_histogram_vec", 0.5)
+
+		assert.Equal(1.0, testutil.ToFloat64(r.GaugeVecs["foo_gauge_vec"].With(labels)))
+		assert.Equal(2.0, testutil.ToFloat64(r.CounterVecs["foo_counter_vec"].With(labels)))
+
+		assert.Nil(r.CloseVec("foo_gauge_vec"))
+		assert.Nil(r.CloseVec("foo_counter_vec"))
+		assert.Nil(r.CloseVec("foo_histogram_vec"))
+		assert.Empty(r.GaugeVecs)
+		assert.Empty(r.CounterVecs)
+		assert.Empty(r.HistogramVecs)
+	})
+
+	t.Run("when NewVecs fails creating labeled metrics", func(t *testing.T) {
+		t.Run("due empty name", func(t *testing.T) {
+			r := newVecRegistry()
+
+			err := r.NewCounterVecs([]string{"job_type"}, "")
+
+			assert.Equal("vec's name should not be empty", err.Error())
+			assert.Empty(r.CounterVecs)
+		})
+
+		t.Run("due re-creating the vec twice", func(t *testing.T) {
+			r := newVecRegistry()
+
+			err := r.NewCounterVecs([]string{"job_type"}, "bar_vec")
+			assert.Nil(err)
+
+			err = r.NewGaugeVecs([]string{"job_type"}, "bar_vec")
+			assert.Equal("vec 'bar_vec' already registered", err.Error())
+			assert.Empty(r.GaugeVecs)
+
+			assert.Nil(r.CloseVec("bar_vec"))
+		})
+	})
+}
+
+func TestCloseVec(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when CloseVec fails on closing a vec because it doesn't exists", func(t *testing.T) {
+		r := newVecRegistry()
+
+		err := r.CloseVec("foo")
+		assert.Equal("vec 'foo' not registered", err.Error())
+	})
+}
@@ -0,0 +1,132 @@
+package thrall
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jcleira/thrall/metrics"
+)
+
+// Handler runs a job, the innermost Handler sets the ctx on Contextual jobs
+// and calls the job's Run().
+type Handler func(ctx context.Context, job Runnable) error
+
+// Middleware wraps a Handler to add cross-cutting behavior around every job,
+// as auth context, tenant tagging, timing or audit logs. A Middleware should
+// call next to keep running the job.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware is an optional func for thrall's init, It does configure a
+// chain of middlewares applied to every job run, the first middleware given
+// is the outermost one. It can be called many times to append middlewares.
+//
+// - middlewares: The middlewares to append to the chain.
+//
+// Returns a optional configuration function.
+func WithMiddleware(middlewares ...Middleware) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.middlewares = append(wp.middlewares, middlewares...)
+	}
+}
+
+// runHandler is the innermost Handler, It runs the job with the given ctx.
+//
+// - ctx: The job's execution context.
+// - job: The job to run.
+//
+// Returns the job's error.
+func runHandler(ctx context.Context, job Runnable) error {
+	if contextual, ok := job.(Contextual); ok {
+		contextual.WithContext(ctx)
+	}
+
+	return job.Run()
+}
+
+// chain builds the Handler that runs every job, wrapping the runHandler with
+// the given middlewares.
+//
+// - middlewares: The middlewares, the first one is the outermost.
+//
+// Returns the chained Handler.
+func chain(middlewares []Middleware) Handler {
+	handler := Handler(runHandler)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// RecoverMiddleware is a Middleware that recovers the job's panics, returning
+// them as job errors instead of crashing the whole process.
+//
+// Returns the Middleware.
+func RecoverMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job Runnable) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("job panic: %v", r)
+				}
+			}()
+
+			return next(ctx, job)
+		}
+	}
+}
+
+// LoggingMiddleware is a Middleware that logs every job run result and
+// duration.
+//
+// - logger: The Logger where the job runs are logged.
+//
+// Returns the Middleware.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job Runnable) error {
+			start := time.Now()
+			err := next(ctx, job)
+
+			if err != nil {
+				logger.Error("job handled", "job_type", jobType(job),
+					"duration", time.Since(start), "error", err)
+			} else {
+				logger.Info("job handled", "job_type", jobType(job),
+					"duration", time.Since(start))
+			}
+
+			return err
+		}
+	}
+}
+
+// MetricsMiddleware is a Middleware that reports every job run duration on
+// the "job_handled_seconds" histogram, labeled by job type and status.
+//
+// - sink: The metrics sink where the metric is reported.
+//
+// Returns the Middleware.
+func MetricsMiddleware(sink metrics.Sink) Middleware {
+	sink.NewHistogramVecs(nil, []string{"job_type", "status"}, "job_handled_seconds")
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job Runnable) error {
+			start := time.Now()
+			err := next(ctx, job)
+
+			status := "success"
+			if err != nil {
+				status = "failure"
+			}
+
+			sink.ObserveWith(map[string]string{
+				"job_type": jobType(job),
+				"status":   status,
+			}, "job_handled_seconds", time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
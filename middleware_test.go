@@ -0,0 +1,91 @@
+package thrall
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+type tenantJob struct {
+	JobContext
+	Tenant atomic.Value
+}
+
+func (tj *tenantJob) Run() error {
+	tj.Tenant.Store(tj.Context().Value(tenantKey{}))
+	return nil
+}
+
+type panicJob struct{}
+
+func (pj *panicJob) Run() error {
+	panic("boom")
+}
+
+func TestWithMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when the middlewares succeed wrapping the jobs in order", func(t *testing.T) {
+		var (
+			mutex sync.Mutex
+			calls []string
+		)
+
+		trace := func(name string) Middleware {
+			return func(next Handler) Handler {
+				return func(ctx context.Context, job Runnable) error {
+					mutex.Lock()
+					calls = append(calls, name)
+					mutex.Unlock()
+
+					return next(ctx, job)
+				}
+			}
+		}
+
+		tenant := func(next Handler) Handler {
+			return func(ctx context.Context, job Runnable) error {
+				return next(context.WithValue(ctx, tenantKey{}, "foo"), job)
+			}
+		}
+
+		queue, _, close := Init(1, WithMiddleware(trace("first"), trace("second")),
+			WithMiddleware(tenant))
+
+		job := &tenantJob{}
+		queue <- job
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		assert.Equal([]string{"first", "second"}, calls)
+		mutex.Unlock()
+		assert.Equal("foo", job.Tenant.Load())
+
+		close <- true
+	})
+
+	t.Run("when RecoverMiddleware succeed recovering a job panic", func(t *testing.T) {
+		queue, errors, close := Init(1, WithMiddleware(
+			RecoverMiddleware(),
+			LoggingMiddleware(NopLogger{}),
+			MetricsMiddleware(metrics.Nop{}),
+		))
+
+		queue <- &panicJob{}
+		select {
+		case err := <-errors:
+			assert.Equal("job error on worker 1. Err: job panic: boom", err.Error())
+		case <-time.After(100 * time.Millisecond):
+			t.Error("Timeout waiting for the job to return an error")
+		}
+
+		close <- true
+	})
+}
@@ -0,0 +1,33 @@
+package thrall
+
+import "reflect"
+
+// Named defines an interface that could be implemented by jobs to set their
+// job type, the Name() func returns the name that would be used to label the
+// job's metrics. The job's Go type name is used for any other job.
+type Named interface {
+	Name() string
+}
+
+// jobType returns the job type for the given job, that's the Named job's
+// Name() or the job's Go type name.
+//
+// - job: The job to get the type from.
+//
+// Returns the job type name.
+func jobType(job Runnable) string {
+	if named, ok := job.(Named); ok {
+		return named.Name()
+	}
+
+	t := reflect.TypeOf(job)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Name() == "" {
+		return t.String()
+	}
+
+	return t.Name()
+}
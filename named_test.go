@@ -0,0 +1,27 @@
+package thrall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namedJob struct {
+	testJob
+}
+
+func (nj *namedJob) Name() string {
+	return "named"
+}
+
+func TestJobType(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when jobType succeed using the job's Go type name", func(t *testing.T) {
+		assert.Equal("testJob", jobType(&testJob{}))
+	})
+
+	t.Run("when jobType succeed using the Named job's name", func(t *testing.T) {
+		assert.Equal("named", jobType(&namedJob{}))
+	})
+}
@@ -0,0 +1,138 @@
+package thrall
+
+// Pause stops thrall from handing jobs to the workers, the jobs are still
+// accepted and held until Resume is called, the scheduled jobs are kept on the
+// scheduler. The jobs already running are not affected.
+//
+// Returns nothing.
+func Pause() {
+	wp.pause("")
+}
+
+// Resume resumes thrall's jobs processing, the held jobs are handed to the
+// workers, except those which job type is still paused.
+//
+// Returns nothing.
+func Resume() {
+	wp.resume("")
+}
+
+// PauseJobType stops thrall from handing the given job type jobs to the
+// workers, as Pause does for all the jobs.
+//
+// - jobType: The job type to pause, check the Named interface.
+//
+// Returns nothing.
+func PauseJobType(jobType string) {
+	wp.pause(jobType)
+}
+
+// ResumeJobType resumes thrall's processing of the given job type jobs.
+//
+// - jobType: The job type to resume, check the Named interface.
+//
+// Returns nothing.
+func ResumeJobType(jobType string) {
+	wp.resume(jobType)
+}
+
+// pause pauses the whole workerPool or a single job type.
+//
+// - t: The job type to pause, the whole workerPool is paused if empty.
+//
+// Returns nothing.
+func (wp *workerPool) pause(t string) {
+	wp.pauseMutex.Lock()
+	defer wp.pauseMutex.Unlock()
+
+	if t == "" {
+		wp.paused = true
+	} else {
+		wp.pausedTypes[t] = true
+	}
+
+	wp.Logger.Info("workerpool paused", "pool", wp.Name, "job_type", t)
+}
+
+// resume resumes the whole workerPool or a single job type, releasing the
+// held jobs that are not paused anymore.
+//
+// - t: The job type to resume, the whole workerPool is resumed if empty.
+//
+// Returns nothing.
+func (wp *workerPool) resume(t string) {
+	wp.pauseMutex.Lock()
+	defer wp.pauseMutex.Unlock()
+
+	if t == "" {
+		wp.paused = false
+	} else {
+		delete(wp.pausedTypes, t)
+	}
+
+	wp.Logger.Info("workerpool resumed", "pool", wp.Name, "job_type", t)
+
+	if wp.paused {
+		return
+	}
+
+	var held, released []*envelope
+	for _, e := range wp.held {
+		if wp.pausedTypes[jobType(e.job)] {
+			held = append(held, e)
+			continue
+		}
+
+		released = append(released, e)
+	}
+	wp.held = held
+
+	go func() {
+		for _, e := range released {
+			wp.DecMetric(e.job, "workerpool_job_held")
+			wp.dispatch(e)
+		}
+	}()
+}
+
+// isPaused checks if the given job is paused.
+//
+// - job: The job to check.
+//
+// Returns true if the workerPool or the job's type are paused.
+func (wp *workerPool) isPaused(job Runnable) bool {
+	wp.pauseMutex.Lock()
+	defer wp.pauseMutex.Unlock()
+
+	return wp.paused || wp.pausedTypes[jobType(job)]
+}
+
+// isPoolPaused checks if the whole workerPool is paused.
+//
+// Returns true if the workerPool is paused.
+func (wp *workerPool) isPoolPaused() bool {
+	wp.pauseMutex.Lock()
+	defer wp.pauseMutex.Unlock()
+
+	return wp.paused
+}
+
+// hold holds the given job if it's paused.
+//
+// - e: The enveloped job to hold.
+//
+// Returns true if the job has been held.
+func (wp *workerPool) hold(e *envelope) bool {
+	wp.pauseMutex.Lock()
+	defer wp.pauseMutex.Unlock()
+
+	if !wp.paused && !wp.pausedTypes[jobType(e.job)] {
+		return false
+	}
+
+	wp.held = append(wp.held, e)
+	wp.IncMetric(e.job, "workerpool_job_held")
+	wp.Logger.Debug("job held", jobFields(e)...)
+
+	return true
+}
@@ -0,0 +1,83 @@
+package thrall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nowJob struct {
+	testJob
+}
+
+func (nj *nowJob) Schedule() time.Time {
+	return time.Now()
+}
+
+func TestPause(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Pause succeed holding the jobs until resumed", func(t *testing.T) {
+		queue, _, close := Init(1)
+
+		Pause()
+
+		var job testJob
+		queue <- &job
+		time.Sleep(10 * time.Millisecond)
+		assert.False(job.Executed.Load())
+		assert.Equal(1, Stats().Held)
+
+		Resume()
+		time.Sleep(10 * time.Millisecond)
+		assert.True(job.Executed.Load())
+		assert.Equal(0, Stats().Held)
+
+		close <- true
+	})
+
+	t.Run("when PauseJobType succeed holding only the given job type", func(t *testing.T) {
+		queue, _, close := Init(1)
+
+		PauseJobType("testJob")
+
+		var job testJob
+		var named namedJob
+		queue <- &job
+		queue <- &named
+		time.Sleep(10 * time.Millisecond)
+		assert.False(job.Executed.Load())
+		assert.True(named.Executed.Load())
+
+		Resume()
+		time.Sleep(10 * time.Millisecond)
+		assert.False(job.Executed.Load())
+
+		ResumeJobType("testJob")
+		time.Sleep(10 * time.Millisecond)
+		assert.True(job.Executed.Load())
+
+		close <- true
+	})
+
+	t.Run("when Pause succeed keeping the scheduled jobs on the scheduler", func(t *testing.T) {
+		queue, _, close := Init(1)
+
+		Pause()
+
+		job := &nowJob{}
+		queue <- job
+		time.Sleep(1100 * time.Millisecond)
+		assert.False(job.Executed.Load())
+		assert.Equal(1, Stats().Scheduled)
+		assert.Equal(0, Stats().Held)
+
+		Resume()
+		time.Sleep(1100 * time.Millisecond)
+		assert.True(job.Executed.Load())
+		assert.Equal(0, Stats().Scheduled)
+
+		close <- true
+	})
+}
@@ -0,0 +1,390 @@
+package thrall
+
+import (
+	"time"
+
+	"github.com/jcleira/thrall/store"
+)
+
+// The stored jobs defaults, the failed jobs are retried after a backoff
+// doubled on every attempt, up to maxRetryBackoff.
+const (
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultMaxAttempts       = 25
+	defaultRetryBackoff      = time.Second
+	maxRetryBackoff          = time.Hour
+)
+
+// WithStore is an optional func for thrall's init, It does configure a
+// durable Store for thrall's jobs. Every received job is encoded and stored
+// before being run, so the jobs survive process restarts, then the jobs are
+// reserved from the Store, run, and acked once they succeed or returned to
+// the Store if they fail.
+//
+// - s: The jobs Store, as a store.FileStore.
+// - codec: The Codec used to encode and decode the jobs.
+//
+// Returns a optional configuration function.
+func WithStore(s store.Store, codec Codec) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.Store = s
+		wp.Codec = codec
+		wp.stored = make(chan bool, 1)
+	}
+}
+
+// WithVisibilityTimeout is an optional func for thrall's init, It does
+// configure the lease of the jobs reserved from the Store. The lease is
+// extended until the job is settled, while it waits for a worker, it's held
+// or it runs, so the job returns to the Store only if the process dies. It
+// defaults to 30 seconds.
+//
+// - timeout: The reservation's visibility timeout.
+//
+// Returns a optional configuration function.
+func WithVisibilityTimeout(timeout time.Duration) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.VisibilityTimeout = timeout
+	}
+}
+
+// WithMaxAttempts is an optional func for thrall's init, It does configure
+// the max number of times that a failing stored job is run, once reached the
+// job is dropped from the Store. It defaults to 25, the failing jobs are
+// retried forever if it's 0.
+//
+// - attempts: The max number of attempts.
+//
+// Returns a optional configuration function.
+func WithMaxAttempts(attempts int) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.MaxAttempts = attempts
+	}
+}
+
+// WithRetryBackoff is an optional func for thrall's init, It does configure
+// the time that a failed stored job waits on the Store before being retried,
+// it's doubled on every attempt up to an hour. It defaults to 1 second.
+//
+// - backoff: The first retry's backoff.
+//
+// Returns a optional configuration function.
+func WithRetryBackoff(backoff time.Duration) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.RetryBackoff = backoff
+	}
+}
+
+// persist encodes and stores a received job, scheduleable jobs are stored
+// with their execution time.
+//
+// - e: The enveloped job to store.
+//
+// Returns true if the job has been stored.
+func (wp *workerPool) persist(e *envelope) bool {
+	name, payload, err := wp.Codec.Encode(e.job)
+	if err != nil {
+		wp.Logger.Error("job not encoded", jobFields(e, "error", err)...)
+		return false
+	}
+
+	job := &store.Job{ID: e.id, Type: name, Payload: payload}
+
+	if scheduleable, ok := e.job.(Scheduleable); ok {
+		err = wp.Store.Schedule(job, scheduleable.Schedule())
+	} else {
+		err = wp.Store.Enqueue(job)
+	}
+
+	if err != nil {
+		wp.Logger.Error("job not stored", jobFields(e, "error", err)...)
+		return false
+	}
+
+	wp.endPhase(e)
+	wp.Logger.Debug("job stored", jobFields(e)...)
+	wp.notifyStored()
+
+	return true
+}
+
+// feed reserves the ready jobs from the Store and dispatches them to the
+// workers until the workerPool is closed, the Store is checked on every
+// stored job and every second for the scheduled and the expired lease ones.
+//
+// Returns nothing.
+func (wp *workerPool) feed() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wp.workersClose:
+			return
+		default:
+		}
+
+		if !wp.isPoolPaused() {
+			job, err := wp.Store.Reserve(wp.VisibilityTimeout)
+			if err == nil {
+				wp.dispatchStored(job)
+				continue
+			}
+
+			if err != store.ErrNoJobs {
+				wp.Logger.Error("job not reserved", "pool", wp.Name, "error", err)
+			}
+		}
+
+		select {
+		case <-wp.stored:
+		case <-ticker.C:
+		case <-wp.workersClose:
+			return
+		}
+	}
+}
+
+// dispatchStored decodes a reserved job and dispatches it to the workers, the
+// jobs that can't be decoded are moved to the Store's dead letters with the
+// decoding error, or returned to the Store after their backoff if the Store
+// doesn't implement store.Admin, so they are never lost.
+//
+// - job: The reserved job.
+//
+// Returns nothing.
+func (wp *workerPool) dispatchStored(job *store.Job) {
+	runnable, err := wp.Codec.Decode(job.Type, job.Payload)
+	if err != nil {
+		wp.Logger.Error("job not decoded", "job_id", job.ID, "job_type", job.Type,
+			"error", err)
+		wp.undecoded(job, err)
+		return
+	}
+
+	e := &envelope{
+		id:       job.ID,
+		job:      runnable,
+		ctx:      jobContext(runnable),
+		stored:   true,
+		attempts: job.Attempts,
+		token:    job.Token,
+		schedule: job.Schedule,
+	}
+	wp.startPhase(e, phaseEnqueue)
+	wp.track(e)
+
+	e.stopHeartbeat = wp.heartbeat(e)
+	e.ready = time.Now()
+	wp.dispatch(e)
+}
+
+// undecoded settles a reserved job that can't be decoded, it's buried with the
+// decoding error if the Store implements store.Admin, otherwise it's nacked.
+//
+// - job: The reserved job.
+// - decodeErr: The decoding error.
+//
+// Returns nothing.
+func (wp *workerPool) undecoded(job *store.Job, decodeErr error) {
+	if admin, ok := wp.Store.(store.Admin); ok {
+		if err := admin.Bury(job.ID, job.Token, decodeErr.Error()); err != nil {
+			wp.Logger.Error("job not buried", "job_id", job.ID, "job_type", job.Type,
+				"error", err)
+		}
+
+		return
+	}
+
+	delay := wp.retryBackoff(job.Attempts)
+	if err := wp.Store.Nack(job.ID, job.Token, delay); err != nil {
+		wp.Logger.Error("job not nacked", "job_id", job.ID, "job_type", job.Type,
+			"error", err)
+		return
+	}
+
+	time.AfterFunc(delay, wp.notifyStored)
+}
+
+// heartbeat extends a stored job's lease every half of the visibility
+// timeout, from it's reservation until it's settled, so the job is not
+// reserved again while it waits for a worker, it's held or it runs.
+//
+// - e: The reserved enveloped job.
+//
+// Returns a func to stop the heartbeat once the job is settled.
+func (wp *workerPool) heartbeat(e *envelope) func() {
+	done := make(chan bool)
+
+	go func() {
+		ticker := time.NewTicker(wp.VisibilityTimeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := wp.Store.Extend(e.id, e.token, wp.VisibilityTimeout); err != nil {
+					wp.Logger.Error("job lease not extended", jobFields(e, "error", err)...)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// settle acks a stored job once it succeed, the failed ones are returned to
+// the Store to be retried after their backoff unless they have reached the
+// max attempts, then they are moved to the Store's dead letters, or dropped
+// if the Store doesn't implement store.Admin.
+//
+// - e: The done enveloped job.
+// - succeed: Whether the job has succeed.
+//
+// Returns nothing.
+func (wp *workerPool) settle(e *envelope, succeed bool) {
+	if succeed {
+		wp.ack(e)
+		return
+	}
+
+	if !wp.retryable(e) {
+		wp.drop(e)
+		return
+	}
+
+	wp.Hooks.retry(e.job)
+	wp.nack(e, wp.retryBackoff(e.attempts))
+}
+
+// drop takes a failed stored job that won't be retried out of the Store, it's
+// moved to the Store's dead letters, or acked if the Store doesn't implement
+// store.Admin.
+//
+// - e: The failed enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) drop(e *envelope) {
+	wp.IncMetric(e.job, "workerpool_job_dropped")
+
+	if admin, ok := wp.Store.(store.Admin); ok {
+		wp.bury(e, admin)
+		return
+	}
+
+	wp.Logger.Warn("job dropped", jobFields(e, "attempts", e.attempts)...)
+	wp.ack(e)
+}
+
+// retryBackoff returns the time that a failed stored job waits before being
+// retried, the RetryBackoff doubled on every attempt up to maxRetryBackoff.
+//
+// - attempts: The job's attempts.
+//
+// Returns the retry backoff.
+func (wp *workerPool) retryBackoff(attempts int) time.Duration {
+	backoff := wp.RetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+
+	return backoff
+}
+
+// bury moves a stored job that has reached the max attempts to the Store's
+// dead letters, with it's last error.
+//
+// - e: The failed enveloped job.
+// - admin: The Store's administration.
+//
+// Returns nothing.
+func (wp *workerPool) bury(e *envelope, admin store.Admin) {
+	wp.stopHeartbeat(e)
+	status, _ := wp.status(e.id)
+
+	if err := admin.Bury(e.id, e.token, status.Error); err != nil {
+		wp.Logger.Error("job not buried", jobFields(e, "error", err)...)
+		return
+	}
+
+	wp.Logger.Warn("job buried", jobFields(e, "attempts", e.attempts)...)
+}
+
+// retryable returns true if a failed job would be retried, only the stored
+// jobs under the max attempts are.
+//
+// - e: The failed enveloped job.
+//
+// Returns true if the job would be retried.
+func (wp *workerPool) retryable(e *envelope) bool {
+	if !e.stored {
+		return false
+	}
+
+	return wp.MaxAttempts == 0 || e.attempts < wp.MaxAttempts
+}
+
+// ack acks a stored job once it's done.
+//
+// - e: The done enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) ack(e *envelope) {
+	wp.stopHeartbeat(e)
+	if err := wp.Store.Ack(e.id, e.token); err != nil {
+		wp.Logger.Error("job not acked", jobFields(e, "error", err)...)
+	}
+}
+
+// nack returns a stored job to the Store, as it happens with the failed jobs
+// or the held paused jobs on shutdown. The feed is woken up once the job is
+// ready again.
+//
+// - e: The enveloped job.
+// - delay: The time to wait before the job is ready, zero for right away.
+//
+// Returns nothing.
+func (wp *workerPool) nack(e *envelope, delay time.Duration) {
+	wp.stopHeartbeat(e)
+	if err := wp.Store.Nack(e.id, e.token, delay); err != nil {
+		wp.Logger.Error("job not nacked", jobFields(e, "error", err)...)
+		return
+	}
+
+	if delay > 0 {
+		time.AfterFunc(delay, wp.notifyStored)
+		return
+	}
+
+	wp.notifyStored()
+}
+
+// stopHeartbeat stops a stored job's lease heartbeat, as it's about to be
+// settled.
+//
+// - e: The settled enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) stopHeartbeat(e *envelope) {
+	if e.stopHeartbeat != nil {
+		e.stopHeartbeat()
+		e.stopHeartbeat = nil
+	}
+}
+
+// notifyStored wakes up the feed goroutine, as there is a job ready on the
+// Store.
+//
+// Returns nothing.
+func (wp *workerPool) notifyStored() {
+	select {
+	case wp.stored <- true:
+	default:
+	}
+}
@@ -0,0 +1,292 @@
+package thrall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storedRuns counts the stored jobs runs by their Key, as the jobs are decoded
+// from the store they can't keep their own counters.
+var storedRuns sync.Map
+
+// storedKey returns a Key for the running test's stored jobs, resetting it's
+// runs count so the test can be run many times.
+func storedKey(t *testing.T) string {
+	storedRuns.Delete(t.Name())
+	return t.Name()
+}
+
+// storedRunsOf returns the number of runs of the stored jobs with the given Key.
+func storedRunsOf(key string) int32 {
+	runs, exists := storedRuns.Load(key)
+	if !exists {
+		return 0
+	}
+
+	return runs.(*atomic.Int32).Load()
+}
+
+type storedJob struct {
+	Key  string
+	Fail int
+}
+
+func (sj *storedJob) Run() error {
+	runs, _ := storedRuns.LoadOrStore(sj.Key, new(atomic.Int32))
+	if runs.(*atomic.Int32).Add(1) <= int32(sj.Fail) {
+		return errors.New("stored job failed")
+	}
+
+	return nil
+}
+
+type storedJobCodec struct{}
+
+func (storedJobCodec) Encode(job Runnable) (string, []byte, error) {
+	payload, err := json.Marshal(job)
+	return "storedJob", payload, err
+}
+
+func (storedJobCodec) Decode(name string, payload []byte) (Runnable, error) {
+	if name != "storedJob" {
+		return nil, fmt.Errorf("job type '%s' not registered", name)
+	}
+
+	job := &storedJob{}
+	return job, json.Unmarshal(payload, job)
+}
+
+func TestWithStore(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when a stored job succeed being run and acked", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		queue, _, close := Init(1, WithStore(fs, storedJobCodec{}))
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key}
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(int32(1), storedRunsOf(key))
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Empty(jobs)
+
+		close <- true
+	})
+
+	t.Run("when a stored job succeed surviving a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jobs.log")
+
+		fs, err := store.NewFileStore(path)
+		assert.Nil(err)
+
+		queue, _, close := Init(1, WithStore(fs, storedJobCodec{}))
+		Pause()
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key}
+		time.Sleep(20 * time.Millisecond)
+		close <- true
+		time.Sleep(10 * time.Millisecond)
+		assert.Nil(fs.Close())
+
+		assert.Equal(int32(0), storedRunsOf(key))
+
+		fs, err = store.NewFileStore(path)
+		assert.Nil(err)
+		defer fs.Close()
+
+		_, _, close = Init(1, WithStore(fs, storedJobCodec{}))
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(int32(1), storedRunsOf(key))
+
+		close <- true
+	})
+
+	t.Run("when a failed stored job succeed being retried", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		var retries atomic.Int32
+		queue, errs, close := Init(1, WithStore(fs, storedJobCodec{}),
+			WithErrorsBuffer(10), WithRetryBackoff(10*time.Millisecond),
+			WithHooks(Hooks{OnRetry: func(Runnable) { retries.Add(1) }}))
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key, Fail: 2}
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Equal(int32(3), storedRunsOf(key))
+		assert.Equal(int32(2), retries.Load())
+		assert.Len(errs, 2)
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Empty(jobs)
+
+		close <- true
+	})
+
+	t.Run("when a failed stored job succeed waiting it's backoff before being retried", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		queue, _, close := Init(1, WithStore(fs, storedJobCodec{}), WithErrorsBuffer(10))
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key, Fail: 5}
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(int32(1), storedRunsOf(key))
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		require.Len(t, jobs, 1)
+		assert.False(jobs[0].Reserved)
+		assert.True(jobs[0].Schedule.After(time.Now()))
+
+		close <- true
+	})
+
+	t.Run("when a failed stored job succeed being buried after max attempts", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		queue, _, close := Init(1, WithStore(fs, storedJobCodec{}),
+			WithErrorsBuffer(10), WithMaxAttempts(2), WithRetryBackoff(time.Millisecond))
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key, Fail: 5}
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(int32(2), storedRunsOf(key))
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Empty(jobs)
+
+		dead, err := fs.DeadLetters()
+		assert.Nil(err)
+		require.Len(t, dead, 1)
+		assert.Equal(2, dead[0].Attempts)
+		assert.Equal("stored job failed", dead[0].Error)
+
+		close <- true
+	})
+
+	t.Run("when a stored job that can't be decoded succeed being buried", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		assert.Nil(fs.Enqueue(&store.Job{ID: "1", Type: "unknownJob"}))
+
+		_, _, close := Init(1, WithStore(fs, storedJobCodec{}))
+		time.Sleep(50 * time.Millisecond)
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Empty(jobs)
+
+		dead, err := fs.DeadLetters()
+		assert.Nil(err)
+		require.Len(t, dead, 1)
+		assert.Equal("1", dead[0].ID)
+		assert.Equal("job type 'unknownJob' not registered", dead[0].Error)
+
+		close <- true
+	})
+
+	t.Run("when a waiting stored job succeed being returned to the store on close", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		queue, _, close := Init(0, WithStore(fs, storedJobCodec{}))
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key}
+		time.Sleep(50 * time.Millisecond)
+
+		close <- true
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(int32(0), storedRunsOf(key))
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		require.Len(t, jobs, 1)
+		assert.False(jobs[0].Reserved)
+		assert.Equal(1, jobs[0].Attempts)
+	})
+
+	t.Run("when a held stored job succeed keeping it's lease", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		queue, _, close := Init(1, WithStore(fs, storedJobCodec{}),
+			WithVisibilityTimeout(200*time.Millisecond))
+		PauseJobType("storedJob")
+
+		key := storedKey(t)
+		queue <- &storedJob{Key: key}
+		time.Sleep(2500 * time.Millisecond)
+		assert.Equal(1, Stats().Held)
+
+		ResumeJobType("storedJob")
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(int32(1), storedRunsOf(key))
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Empty(jobs)
+
+		close <- true
+	})
+
+	t.Run("when a running stored job succeed extending it's lease", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		wp := &workerPool{Store: fs, VisibilityTimeout: 20 * time.Millisecond,
+			Logger: NopLogger{}}
+
+		assert.Nil(fs.Enqueue(&store.Job{ID: "1"}))
+		reserved, err := fs.Reserve(wp.VisibilityTimeout)
+		assert.Nil(err)
+
+		stop := wp.heartbeat(&envelope{id: "1", token: reserved.Token})
+		time.Sleep(50 * time.Millisecond)
+
+		_, err = fs.Reserve(time.Minute)
+		assert.Equal(store.ErrNoJobs, err)
+
+		stop()
+		time.Sleep(30 * time.Millisecond)
+
+		job, err := fs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+	})
+}
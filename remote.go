@@ -0,0 +1,381 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// remoteReapInterval is how often the expired remote leases are checked.
+const remoteReapInterval = 100 * time.Millisecond
+
+// The remote workers errors.
+var (
+	ErrPoolClosed     = errors.New("workerpool closed")
+	ErrLeaseNotFound  = errors.New("lease not found")
+	ErrRemoteDisabled = errors.New("remote workers not enabled")
+)
+
+// RemoteJob is a job leased to a remote worker, encoded with the workerPool
+// Codec so the remote worker can decode it with the same job types.
+type RemoteJob struct {
+	ID string
+
+	// Token identifies the job's lease, it's required to extend or complete
+	// it, so a job leased again once it's lease expired can't be extended or
+	// completed by it's former remote worker.
+	Token string
+
+	Type     string
+	Payload  []byte
+	Attempts int
+	Deadline time.Time
+}
+
+// remoteLease is a job leased to a remote worker.
+type remoteLease struct {
+	e        *envelope
+	token    string
+	worker   string
+	started  time.Time
+	deadline time.Time
+}
+
+// WithRemoteWorkers is an optional func for thrall's init, It does allow
+// workers running on other processes to lease thrall's jobs, as the rpc
+// package's RemoteWorker does. The workerPool keeps owning the jobs
+// scheduling and limits, the leased jobs are encoded with the given Codec and
+// returned to the queue if their lease expires before they are completed.
+//
+// - codec: The Codec used to encode the leased jobs.
+//
+// Returns a optional configuration function.
+func WithRemoteWorkers(codec Codec) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.Codec = codec
+		wp.remoteLeases = make(map[string]*remoteLease)
+	}
+}
+
+// LeaseJob leases the next job to a remote worker, it blocks until a job is
+// ready or the context is done. The job must be completed with CompleteJob
+// before the lease deadline, or extended with ExtendLease, otherwise it's
+// returned to the queue.
+//
+// - ctx: The lease context.
+// - worker: The remote worker ID.
+// - visibility: The lease duration.
+//
+// Returns the leased job, or an error if the context is done or thrall is
+// closed.
+func LeaseJob(ctx context.Context, worker string, visibility time.Duration) (*RemoteJob, error) {
+	return wp.leaseJob(ctx, worker, visibility)
+}
+
+// ExtendLease extends a remote job's lease, as the remote worker's heartbeat.
+//
+// - id: The job ID.
+// - token: The lease token, check RemoteJob.
+// - visibility: The lease duration, from now.
+//
+// Returns true if the job has been canceled and the remote worker should
+// stop it, or ErrLeaseNotFound if the lease has expired.
+func ExtendLease(id, token string, visibility time.Duration) (bool, error) {
+	return wp.extendLease(id, token, visibility)
+}
+
+// CompleteJob reports a remote job's result.
+//
+// - id: The job ID.
+// - token: The lease token, check RemoteJob.
+// - jobErr: The job's error, nil if it succeed.
+//
+// Returns ErrLeaseNotFound if the lease has expired.
+func CompleteJob(id, token string, jobErr error) error {
+	return wp.completeJob(id, token, jobErr)
+}
+
+// leaseJob leases the next job to a remote worker.
+//
+// - ctx: The lease context.
+// - worker: The remote worker ID.
+// - visibility: The lease duration.
+//
+// Returns the leased job, or an error if the context is done or the
+// workerPool is closed.
+func (wp *workerPool) leaseJob(ctx context.Context, worker string, visibility time.Duration) (*RemoteJob, error) {
+	if wp.remoteLeases == nil {
+		return nil, ErrRemoteDisabled
+	}
+
+	for {
+		var e *envelope
+
+		select {
+		case e = <-wp.workersQueue:
+			wp.queued.Add(-1)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wp.workersClose:
+			return nil, ErrPoolClosed
+		}
+
+		if wp.skipCanceled(e) {
+			continue
+		}
+
+		// The job is encoded before adquiring the limiter, so the jobs that
+		// can't be encoded don't take a limiter slot.
+		wp.startUnique(e)
+		name, payload, err := wp.Codec.Encode(e.job)
+		if err != nil {
+			wp.failUnencoded(e, worker, err)
+			continue
+		}
+
+		if e.phase != phaseLimiter {
+			wp.startPhase(e, phaseLimiter)
+		}
+
+		if !wp.Limiter.Adquire() {
+			wp.stopUnique(e)
+			wp.IncMetric(e.job, "workerpool_job_rate_limited")
+			e.span.AddEvent("thrall.rate_limited")
+			wp.Hooks.retry(e.job)
+			go wp.dispatch(e)
+			continue
+		}
+
+		wp.ObserveMetric(e.job, "job_wait_seconds", time.Since(e.ready).Seconds())
+		wp.startPhase(e, phaseRun, attribute.String("thrall.remote_worker", worker))
+		wp.busy.Add(1)
+
+		wp.Logger.Debug("job leased", jobFields(e, "remote_worker", worker)...)
+		wp.Hooks.start(e.job)
+		wp.startStatus(e, worker)
+
+		lease := &remoteLease{
+			e:        e,
+			token:    newID(),
+			worker:   worker,
+			started:  time.Now(),
+			deadline: time.Now().Add(visibility),
+		}
+
+		wp.remoteMutex.Lock()
+		wp.remoteLeases[e.id] = lease
+		wp.remoteMutex.Unlock()
+
+		status, _ := wp.status(e.id)
+
+		return &RemoteJob{
+			ID:       e.id,
+			Token:    lease.token,
+			Type:     name,
+			Payload:  payload,
+			Attempts: status.Attempts,
+			Deadline: lease.deadline,
+		}, nil
+	}
+}
+
+// extendLease extends a remote job's lease.
+//
+// - id: The job ID.
+// - token: The lease token.
+// - visibility: The lease duration, from now.
+//
+// Returns true if the job has been canceled, or an error if the lease has
+// expired.
+func (wp *workerPool) extendLease(id, token string, visibility time.Duration) (bool, error) {
+	wp.remoteMutex.Lock()
+	lease, exists := wp.remoteLeases[id]
+	exists = exists && lease.token == token
+	if exists {
+		lease.deadline = time.Now().Add(visibility)
+	}
+	wp.remoteMutex.Unlock()
+
+	if !exists {
+		return false, ErrLeaseNotFound
+	}
+
+	wp.statusMutex.Lock()
+	canceled := wp.canceled[id]
+	wp.statusMutex.Unlock()
+
+	return canceled, nil
+}
+
+// completeJob reports a remote job's result.
+//
+// - id: The job ID.
+// - token: The lease token.
+// - jobErr: The job's error, nil if it succeed.
+//
+// Returns an error if the lease has expired.
+func (wp *workerPool) completeJob(id, token string, jobErr error) error {
+	lease := wp.releaseLease(id, token)
+	if lease == nil {
+		return ErrLeaseNotFound
+	}
+
+	e := lease.e
+	duration := time.Since(lease.started)
+	wp.ObserveMetric(e.job, "job_run_seconds", duration.Seconds())
+
+	if jobErr != nil {
+		e.span.RecordError(jobErr)
+		e.span.SetStatus(codes.Error, jobErr.Error())
+		wp.Logger.Error("job failed", jobFields(e,
+			"remote_worker", lease.worker,
+			"duration", duration,
+			"error", jobErr,
+		)...)
+		wp.IncMetric(e.job, "workerpool_job_erroed")
+	} else {
+		wp.Hooks.success(e.job)
+		wp.Logger.Debug("job finished", jobFields(e,
+			"remote_worker", lease.worker,
+			"duration", duration,
+		)...)
+	}
+
+	wp.IncMetric(e.job, "workerpool_job_processed")
+	wp.endPhase(e)
+	wp.finishRemote(e, lease.worker, jobErr)
+
+	if repeatable, ok := e.job.(Repeateable); ok && repeatable.Repeat() {
+		go func() { wp.Queue <- e.job }()
+	}
+
+	return nil
+}
+
+// failUnencoded fails a job that can't be encoded for the remote workers, it's
+// not retried as it would never be encoded. The stored jobs are moved to the
+// Store's dead letters, or dropped if the Store doesn't implement
+// store.Admin.
+//
+// - e: The enveloped job.
+// - worker: The remote worker ID.
+// - err: The encoding error.
+//
+// Returns nothing.
+func (wp *workerPool) failUnencoded(e *envelope, worker string, err error) {
+	wp.endPhase(e)
+	wp.Logger.Error("job not encoded", jobFields(e, "remote_worker", worker, "error", err)...)
+
+	je := newJobError(e, 0)
+	je.RemoteWorker = worker
+	je.Err = err
+	wp.Hooks.failure(e.job, je)
+	wp.reportError(je)
+
+	wp.finishStatus(e, err, false)
+
+	if e.stored {
+		wp.drop(e)
+	}
+}
+
+// finishRemote finishes a remote job, reporting it's error if any.
+//
+// - e: The enveloped job.
+// - worker: The remote worker ID.
+// - jobErr: The job's error, nil if it succeed.
+//
+// Returns nothing.
+func (wp *workerPool) finishRemote(e *envelope, worker string, jobErr error) {
+	if jobErr != nil {
+		je := newJobError(e, 0)
+		je.RemoteWorker = worker
+		je.Err = jobErr
+		wp.Hooks.failure(e.job, je)
+		wp.reportError(je)
+	}
+
+	canceled := wp.finishStatus(e, jobErr, jobErr != nil && wp.retryable(e))
+
+	if e.stored {
+		wp.settle(e, jobErr == nil || canceled)
+	}
+}
+
+// releaseLease removes a remote lease, releasing it's limiter slot.
+//
+// - id: The job ID.
+// - token: The lease token.
+//
+// Returns the removed lease, nil if not found.
+func (wp *workerPool) releaseLease(id, token string) *remoteLease {
+	wp.remoteMutex.Lock()
+	lease, exists := wp.remoteLeases[id]
+	exists = exists && lease.token == token
+	if exists {
+		delete(wp.remoteLeases, id)
+	}
+	wp.remoteMutex.Unlock()
+
+	if !exists {
+		return nil
+	}
+
+	wp.Limiter.Release()
+	wp.busy.Add(-1)
+
+	return lease
+}
+
+// reapLeases returns the remote jobs with an expired lease to the queue, as
+// their remote worker is considered gone, until the workerPool is closed.
+//
+// Returns nothing.
+func (wp *workerPool) reapLeases() {
+	ticker := time.NewTicker(remoteReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-wp.workersClose:
+			return
+		}
+
+		now := time.Now()
+
+		var expired []*remoteLease
+		wp.remoteMutex.Lock()
+		for _, lease := range wp.remoteLeases {
+			if lease.deadline.Before(now) {
+				expired = append(expired, lease)
+			}
+		}
+		wp.remoteMutex.Unlock()
+
+		for _, lease := range expired {
+			lease := wp.releaseLease(lease.e.id, lease.token)
+			if lease == nil {
+				continue
+			}
+
+			e := lease.e
+			e.span.AddEvent("thrall.lease_expired")
+			wp.endPhase(e)
+			wp.Logger.Warn("job lease expired", jobFields(e, "remote_worker", lease.worker)...)
+			wp.IncMetric(e.job, "workerpool_job_lease_expired")
+			wp.Hooks.retry(e.job)
+			wp.stopUnique(e)
+			wp.updateStatus(e, func(status *JobStatus) {
+				status.State = StateQueued
+			})
+
+			wp.startPhase(e, phaseEnqueue)
+			e.ready = time.Now()
+			go wp.dispatch(e)
+		}
+	}
+}
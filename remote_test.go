@@ -0,0 +1,209 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onceCodec is a storedJobCodec that encodes the jobs only once, so they are
+// stored but can't be leased.
+type onceCodec struct {
+	storedJobCodec
+	encoded atomic.Bool
+}
+
+func (oc *onceCodec) Encode(job Runnable) (string, []byte, error) {
+	if oc.encoded.Swap(true) {
+		return "", nil, errors.New("job not encodable")
+	}
+
+	return oc.storedJobCodec.Encode(job)
+}
+
+func TestRemoteWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	lease := func() (*RemoteJob, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		return LeaseJob(ctx, "remote-1", time.Second)
+	}
+
+	t.Run("when LeaseJob succeed leasing a job to a remote worker", func(t *testing.T) {
+		_, _, close := Init(0, WithRemoteWorkers(NewJSONCodec(newTestTypes())))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&emailJob{To: "foo@bar.com"})
+
+		job, err := lease()
+		assert.Nil(err)
+		assert.Equal(id, job.ID)
+		assert.Equal("email", job.Type)
+		assert.JSONEq(`{"To":"foo@bar.com","Subject":""}`, string(job.Payload))
+		assert.Equal(1, job.Attempts)
+		assert.Equal(1, Stats().Busy)
+
+		status, _ := Status(id)
+		assert.Equal(StateRunning, status.State)
+
+		canceled, err := ExtendLease(id, job.Token, time.Second)
+		assert.Nil(err)
+		assert.False(canceled)
+
+		assert.Nil(CompleteJob(id, job.Token, nil))
+		assert.Equal(0, Stats().Busy)
+
+		status, _ = Status(id)
+		assert.Equal(StateSucceeded, status.State)
+	})
+
+	t.Run("when CompleteJob succeed reporting a remote job error", func(t *testing.T) {
+		_, _, close := Init(0, WithRemoteWorkers(NewJSONCodec(newTestTypes())),
+			WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&emailJob{})
+
+		job, err := lease()
+		assert.Nil(err)
+		assert.Nil(CompleteJob(id, job.Token, errors.New("error!")))
+
+		status, _ := Status(id)
+		assert.Equal(StateFailed, status.State)
+		assert.Equal("error!", status.Error)
+		assert.Equal("job error on remote worker remote-1. Err: error!",
+			(<-wp.errors).Error())
+	})
+
+	t.Run("when an expired lease succeed returning the job to the queue", func(t *testing.T) {
+		_, _, close := Init(0, WithRemoteWorkers(NewJSONCodec(newTestTypes())))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&emailJob{})
+
+		expired, err := LeaseJob(context.Background(), "remote-1", 10*time.Millisecond)
+		assert.Nil(err)
+
+		time.Sleep(150 * time.Millisecond)
+
+		status, _ := Status(id)
+		assert.Equal(StateQueued, status.State)
+		assert.Equal(ErrLeaseNotFound, CompleteJob(id, expired.Token, nil))
+
+		job, err := lease()
+		assert.Nil(err)
+		assert.Equal(id, job.ID)
+		assert.Equal(2, job.Attempts)
+		assert.NotEqual(expired.Token, job.Token)
+
+		_, err = ExtendLease(id, expired.Token, time.Second)
+		assert.Equal(ErrLeaseNotFound, err)
+		assert.Equal(ErrLeaseNotFound, CompleteJob(id, expired.Token, nil))
+
+		status, _ = Status(id)
+		assert.Equal(StateRunning, status.State)
+		assert.Nil(CompleteJob(id, job.Token, nil))
+	})
+
+	t.Run("when an expired lease succeed handling the job's duplicates again", func(t *testing.T) {
+		types := newTestTypes()
+		types.Register("unique", func() Runnable { return &uniqueJob{} })
+
+		_, _, close := Init(0, WithRemoteWorkers(NewJSONCodec(types)),
+			WithUniquePolicy(UniqueKeepLatest))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&uniqueJob{Key: "foo", Value: "first"})
+
+		_, err := LeaseJob(context.Background(), "remote-1", 10*time.Millisecond)
+		assert.Nil(err)
+
+		time.Sleep(150 * time.Millisecond)
+
+		latest, err := Submit(&uniqueJob{Key: "foo", Value: "second"})
+		assert.Nil(err)
+		assert.Equal(id, latest)
+
+		job, err := lease()
+		assert.Nil(err)
+		assert.Equal(id, job.ID)
+		assert.Contains(string(job.Payload), `"Value":"second"`)
+		assert.Nil(CompleteJob(id, job.Token, nil))
+	})
+
+	t.Run("when ExtendLease succeed reporting a canceled job", func(t *testing.T) {
+		_, _, close := Init(0, WithRemoteWorkers(NewJSONCodec(newTestTypes())),
+			WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&emailJob{})
+
+		job, err := lease()
+		assert.Nil(err)
+		assert.Nil(Cancel(id))
+
+		canceled, err := ExtendLease(id, job.Token, time.Second)
+		assert.Nil(err)
+		assert.True(canceled)
+
+		assert.Nil(CompleteJob(id, job.Token, context.Canceled))
+
+		status, _ := Status(id)
+		assert.Equal(StateCanceled, status.State)
+		assert.Empty(DeadLetters())
+	})
+
+	t.Run("when a job that can't be encoded succeed being buried without a retry", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		codec := &onceCodec{}
+		_, _, close := Init(0, WithRemoteWorkers(codec), WithStore(fs, codec),
+			WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		id, _ := Submit(&storedJob{Key: storedKey(t)})
+
+		_, err = lease()
+		assert.Equal(context.DeadlineExceeded, err)
+
+		status, _ := Status(id)
+		assert.Equal(StateFailed, status.State)
+		assert.Equal(0, Stats().Busy)
+		assert.Equal(0, wp.Limiter.Stats().InUse)
+
+		dead, err := fs.DeadLetters()
+		assert.Nil(err)
+		require.Len(t, dead, 1)
+		assert.Equal(id, dead[0].ID)
+		assert.Equal(1, dead[0].Attempts)
+	})
+
+	t.Run("when LeaseJob fails", func(t *testing.T) {
+		t.Run("due the remote workers not enabled", func(t *testing.T) {
+			_, _, close := Init(0)
+			defer func() { close <- true }()
+
+			_, err := lease()
+			assert.Equal(ErrRemoteDisabled, err)
+		})
+
+		t.Run("due no job ready", func(t *testing.T) {
+			_, _, close := Init(0, WithRemoteWorkers(NewJSONCodec(newTestTypes())))
+			defer func() { close <- true }()
+
+			_, err := lease()
+			assert.Equal(context.DeadlineExceeded, err)
+		})
+	})
+}
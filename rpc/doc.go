@@ -0,0 +1,14 @@
+/*
+Package rpc provides a gRPC service for thrall's workers.
+
+It does implement the Thrall service defined on thrall.proto, so services
+written on any language can submit jobs, by their registered type name with a
+JSON payload, cancel them and track their status. It does also implement the
+Workers service, served by the Coordinator, that leases the jobs of a thrall
+initialized with thrall.WithRemoteWorkers to the RemoteWorker running on
+other processes. The thrall.pb.go and thrall_grpc.pb.go files are generated
+with buf from thrall.proto.
+*/
+package rpc
+
+//go:generate buf generate
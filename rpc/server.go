@@ -0,0 +1,205 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server is the Thrall gRPC service implementation, register it on a
+// grpc.Server with RegisterThrallServer.
+type Server struct {
+	UnimplementedThrallServer
+
+	// Codec decodes the submitted jobs.
+	Codec thrall.Codec
+}
+
+// NewServer creates the Thrall gRPC service.
+//
+// - types: The registry of the job types that can be submitted.
+//
+// Returns the Server.
+func NewServer(types *thrall.TypeRegistry) *Server {
+	return &Server{
+		Codec: thrall.NewJSONCodec(types),
+	}
+}
+
+// Submit decodes and submits a job.
+func (s *Server) Submit(ctx context.Context, req *SubmitRequest) (*SubmitResponse, error) {
+	job, err := s.decode(req.GetJob())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := submit(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SubmitResponse{Id: id}, nil
+}
+
+// SubmitBatch decodes and submits many jobs, no job is submitted if any of
+// them can't be decoded. The jobs are submitted in order, if one fails the
+// rest are not submitted and the error status carries a SubmitBatchFailure
+// detail with the submitted jobs IDs, so a client retries only the rest.
+func (s *Server) SubmitBatch(ctx context.Context, req *SubmitBatchRequest) (*SubmitBatchResponse, error) {
+	jobs := make([]thrall.Runnable, len(req.GetJobs()))
+	for i, j := range req.GetJobs() {
+		job, err := s.decode(j)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "job %d: %s",
+				i, status.Convert(err).Message())
+		}
+
+		jobs[i] = job
+	}
+
+	ids := make([]string, 0, len(jobs))
+	for i, job := range jobs {
+		id, err := submit(job)
+		if err != nil {
+			return nil, batchError(err, i, ids)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return &SubmitBatchResponse{Ids: ids}, nil
+}
+
+// Cancel cancels a job.
+func (s *Server) Cancel(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+	switch err := thrall.Cancel(req.GetId()); err {
+	case nil:
+		return &CancelResponse{}, nil
+	case thrall.ErrJobNotFound:
+		return nil, status.Errorf(codes.NotFound, "job '%s' not found", req.GetId())
+	case thrall.ErrJobFinished:
+		return nil, status.Errorf(codes.FailedPrecondition, "job '%s' already finished",
+			req.GetId())
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+}
+
+// GetStatus returns a job's status.
+func (s *Server) GetStatus(ctx context.Context, req *GetStatusRequest) (*JobStatus, error) {
+	js, exists := thrall.Status(req.GetId())
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "job '%s' not found", req.GetId())
+	}
+
+	return toProto(js), nil
+}
+
+// WatchJob streams a job's status on every change until it's finished.
+func (s *Server) WatchJob(req *WatchJobRequest, stream grpc.ServerStreamingServer[JobStatus]) error {
+	err := thrall.WatchStatus(stream.Context(), req.GetId(), func(js thrall.JobStatus) error {
+		return stream.Send(toProto(js))
+	})
+
+	if errors.Is(err, thrall.ErrJobNotFound) {
+		return status.Errorf(codes.NotFound, "job '%s' not found", req.GetId())
+	}
+
+	return err
+}
+
+// decode decodes a submitted job.
+//
+// - job: The submitted job.
+//
+// Returns the decoded job, or an InvalidArgument error.
+func (s *Server) decode(job *Job) (thrall.Runnable, error) {
+	if job == nil {
+		return nil, status.Error(codes.InvalidArgument, "job is required")
+	}
+
+	payload := job.GetPayload()
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	runnable, err := s.Codec.Decode(job.GetType(), payload)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return runnable, nil
+}
+
+// submit submits a job to thrall.
+//
+// - job: The job to submit.
+//
+// Returns the job ID, an AlreadyExists error if the job is a rejected
+// duplicate, a ResourceExhausted error if the submitted jobs queue is full or
+// an Unavailable error if thrall is closed.
+func submit(job thrall.Runnable) (string, error) {
+	switch id, err := thrall.Submit(job); err {
+	case nil:
+		return id, nil
+	case thrall.ErrDuplicateJob:
+		return "", status.Error(codes.AlreadyExists, err.Error())
+	case thrall.ErrQueueFull:
+		return "", status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return "", status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+// batchError prefixes a SubmitBatch job's submit error with the job's index,
+// attaching the IDs of the jobs submitted before it.
+//
+// - err: The job's submit error.
+// - index: The job's index.
+// - ids: The submitted jobs IDs.
+//
+// Returns the SubmitBatch error status.
+func batchError(err error, index int, ids []string) error {
+	st := status.Newf(status.Code(err), "job %d: %s", index, status.Convert(err).Message())
+
+	detailed, derr := st.WithDetails(&SubmitBatchFailure{Ids: ids, FailedIndex: int32(index)})
+	if derr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// toProto converts a thrall.JobStatus to it's protobuf message.
+//
+// - js: The job status.
+//
+// Returns the protobuf job status.
+func toProto(js thrall.JobStatus) *JobStatus {
+	return &JobStatus{
+		Id:       js.ID,
+		Type:     js.Type,
+		State:    string(js.State),
+		Enqueued: timestamp(js.Enqueued),
+		Schedule: timestamp(js.Schedule),
+		Started:  timestamp(js.Started),
+		Finished: timestamp(js.Finished),
+		Attempts: int32(js.Attempts),
+		Error:    js.Error,
+	}
+}
+
+// timestamp converts a time to a protobuf timestamp, nil for the zero time.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
@@ -0,0 +1,220 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type sleepJob struct {
+	thrall.JobContext
+	Millis int  `json:"millis"`
+	Fail   bool `json:"fail"`
+}
+
+func (sj *sleepJob) Run() error {
+	select {
+	case <-sj.Context().Done():
+		return sj.Context().Err()
+	case <-time.After(time.Duration(sj.Millis) * time.Millisecond):
+	}
+
+	if sj.Fail {
+		return errors.New("sleep failed")
+	}
+
+	return nil
+}
+
+type uniqueSleepJob struct {
+	sleepJob
+	Key string `json:"key"`
+}
+
+func (uj *uniqueSleepJob) UniqueKey() string {
+	return uj.Key
+}
+
+func (uj *uniqueSleepJob) UniqueTTL() time.Duration {
+	return 0
+}
+
+func newTestTypes() *thrall.TypeRegistry {
+	types := thrall.NewTypeRegistry()
+	types.Register("sleep", func() thrall.Runnable { return &sleepJob{} })
+	types.Register("uniqueSleep", func() thrall.Runnable { return &uniqueSleepJob{} })
+
+	return types
+}
+
+func newTestConn(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func newTestClient(t *testing.T) ThrallClient {
+	s := NewServer(newTestTypes())
+
+	return NewThrallClient(newTestConn(t, func(server *grpc.Server) {
+		RegisterThrallServer(server, s)
+	}))
+}
+
+func TestServer(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, close := thrall.Init(2, thrall.WithErrorsBuffer(10))
+	defer func() { close <- true }()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	t.Run("when Submit succeed running a job", func(t *testing.T) {
+		res, err := client.Submit(ctx, &SubmitRequest{
+			Job: &Job{Type: "sleep", Payload: []byte(`{"millis":1}`)},
+		})
+		assert.Nil(err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		js, err := client.GetStatus(ctx, &GetStatusRequest{Id: res.GetId()})
+		assert.Nil(err)
+		assert.Equal("sleepJob", js.GetType())
+		assert.Equal("succeeded", js.GetState())
+		assert.Equal(int32(1), js.GetAttempts())
+		assert.NotNil(js.GetFinished())
+	})
+
+	t.Run("when SubmitBatch succeed submitting many jobs", func(t *testing.T) {
+		res, err := client.SubmitBatch(ctx, &SubmitBatchRequest{Jobs: []*Job{
+			{Type: "sleep"},
+			{Type: "sleep", Payload: []byte(`{"fail":true}`)},
+		}})
+		assert.Nil(err)
+		assert.Len(res.GetIds(), 2)
+
+		time.Sleep(20 * time.Millisecond)
+
+		js, err := client.GetStatus(ctx, &GetStatusRequest{Id: res.GetIds()[1]})
+		assert.Nil(err)
+		assert.Equal("failed", js.GetState())
+		assert.Equal("sleep failed", js.GetError())
+	})
+
+	t.Run("when SubmitBatch fails returning the jobs submitted before the failed one", func(t *testing.T) {
+		unique := &Job{Type: "uniqueSleep", Payload: []byte(`{"key":"batch","millis":100}`)}
+
+		_, err := client.SubmitBatch(ctx, &SubmitBatchRequest{Jobs: []*Job{
+			unique,
+			{Type: "sleep"},
+			unique,
+			{Type: "sleep"},
+		}})
+
+		st := status.Convert(err)
+		assert.Equal(codes.AlreadyExists, st.Code())
+		assert.Equal("job 2: duplicate job", st.Message())
+
+		details := st.Details()
+		if assert.Len(details, 1) {
+			failure := details[0].(*SubmitBatchFailure)
+			assert.Len(failure.GetIds(), 2)
+			assert.Equal(int32(2), failure.GetFailedIndex())
+		}
+	})
+
+	t.Run("when WatchJob succeed streaming a job's status changes", func(t *testing.T) {
+		res, err := client.Submit(ctx, &SubmitRequest{
+			Job: &Job{Type: "sleep", Payload: []byte(`{"millis":30}`)},
+		})
+		assert.Nil(err)
+
+		stream, err := client.WatchJob(ctx, &WatchJobRequest{Id: res.GetId()})
+		assert.Nil(err)
+
+		var states []string
+		for {
+			js, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(err)
+
+			states = append(states, js.GetState())
+		}
+
+		assert.Contains(states, "running")
+		assert.Equal("succeeded", states[len(states)-1])
+	})
+
+	t.Run("when Cancel succeed canceling a running job", func(t *testing.T) {
+		res, err := client.Submit(ctx, &SubmitRequest{
+			Job: &Job{Type: "sleep", Payload: []byte(`{"millis":1000}`)},
+		})
+		assert.Nil(err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = client.Cancel(ctx, &CancelRequest{Id: res.GetId()})
+		assert.Nil(err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		js, err := client.GetStatus(ctx, &GetStatusRequest{Id: res.GetId()})
+		assert.Nil(err)
+		assert.Equal("canceled", js.GetState())
+
+		_, err = client.Cancel(ctx, &CancelRequest{Id: res.GetId()})
+		assert.Equal(codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("when Submit fails due a not registered job type", func(t *testing.T) {
+		_, err := client.Submit(ctx, &SubmitRequest{Job: &Job{Type: "foo"}})
+		assert.Equal(codes.InvalidArgument, status.Code(err))
+
+		_, err = client.SubmitBatch(ctx, &SubmitBatchRequest{Jobs: []*Job{
+			{Type: "sleep"}, {Type: "foo"},
+		}})
+		assert.Equal(codes.InvalidArgument, status.Code(err))
+		assert.Equal("job 1: job type 'foo' not registered", status.Convert(err).Message())
+	})
+
+	t.Run("when GetStatus fails due an unknown job", func(t *testing.T) {
+		_, err := client.GetStatus(ctx, &GetStatusRequest{Id: "foo"})
+		assert.Equal(codes.NotFound, status.Code(err))
+
+		stream, err := client.WatchJob(ctx, &WatchJobRequest{Id: "foo"})
+		assert.Nil(err)
+
+		_, err = stream.Recv()
+		assert.Equal(codes.NotFound, status.Code(err))
+	})
+}
@@ -0,0 +1,487 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: thrall.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Thrall_Submit_FullMethodName      = "/thrall.v1.Thrall/Submit"
+	Thrall_SubmitBatch_FullMethodName = "/thrall.v1.Thrall/SubmitBatch"
+	Thrall_Cancel_FullMethodName      = "/thrall.v1.Thrall/Cancel"
+	Thrall_GetStatus_FullMethodName   = "/thrall.v1.Thrall/GetStatus"
+	Thrall_WatchJob_FullMethodName    = "/thrall.v1.Thrall/WatchJob"
+)
+
+// ThrallClient is the client API for Thrall service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Thrall submits jobs to a thrall's workers pool and tracks them.
+type ThrallClient interface {
+	// Submit submits a job, it returns the job ID.
+	Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+	// SubmitBatch submits many jobs, it returns their IDs in order. If a job
+	// fails to be submitted the error carries a SubmitBatchFailure detail.
+	SubmitBatch(ctx context.Context, in *SubmitBatchRequest, opts ...grpc.CallOption) (*SubmitBatchResponse, error)
+	// Cancel cancels a job that is not finished yet.
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
+	// GetStatus returns a job's status.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*JobStatus, error)
+	// WatchJob streams a job's status on every change until it's finished.
+	WatchJob(ctx context.Context, in *WatchJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobStatus], error)
+}
+
+type thrallClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThrallClient(cc grpc.ClientConnInterface) ThrallClient {
+	return &thrallClient{cc}
+}
+
+func (c *thrallClient) Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitResponse)
+	err := c.cc.Invoke(ctx, Thrall_Submit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thrallClient) SubmitBatch(ctx context.Context, in *SubmitBatchRequest, opts ...grpc.CallOption) (*SubmitBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitBatchResponse)
+	err := c.cc.Invoke(ctx, Thrall_SubmitBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thrallClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelResponse)
+	err := c.cc.Invoke(ctx, Thrall_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thrallClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*JobStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobStatus)
+	err := c.cc.Invoke(ctx, Thrall_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *thrallClient) WatchJob(ctx context.Context, in *WatchJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobStatus], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Thrall_ServiceDesc.Streams[0], Thrall_WatchJob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchJobRequest, JobStatus]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Thrall_WatchJobClient = grpc.ServerStreamingClient[JobStatus]
+
+// ThrallServer is the server API for Thrall service.
+// All implementations must embed UnimplementedThrallServer
+// for forward compatibility.
+//
+// Thrall submits jobs to a thrall's workers pool and tracks them.
+type ThrallServer interface {
+	// Submit submits a job, it returns the job ID.
+	Submit(context.Context, *SubmitRequest) (*SubmitResponse, error)
+	// SubmitBatch submits many jobs, it returns their IDs in order. If a job
+	// fails to be submitted the error carries a SubmitBatchFailure detail.
+	SubmitBatch(context.Context, *SubmitBatchRequest) (*SubmitBatchResponse, error)
+	// Cancel cancels a job that is not finished yet.
+	Cancel(context.Context, *CancelRequest) (*CancelResponse, error)
+	// GetStatus returns a job's status.
+	GetStatus(context.Context, *GetStatusRequest) (*JobStatus, error)
+	// WatchJob streams a job's status on every change until it's finished.
+	WatchJob(*WatchJobRequest, grpc.ServerStreamingServer[JobStatus]) error
+	mustEmbedUnimplementedThrallServer()
+}
+
+// UnimplementedThrallServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedThrallServer struct{}
+
+func (UnimplementedThrallServer) Submit(context.Context, *SubmitRequest) (*SubmitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedThrallServer) SubmitBatch(context.Context, *SubmitBatchRequest) (*SubmitBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitBatch not implemented")
+}
+func (UnimplementedThrallServer) Cancel(context.Context, *CancelRequest) (*CancelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedThrallServer) GetStatus(context.Context, *GetStatusRequest) (*JobStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedThrallServer) WatchJob(*WatchJobRequest, grpc.ServerStreamingServer[JobStatus]) error {
+	return status.Error(codes.Unimplemented, "method WatchJob not implemented")
+}
+func (UnimplementedThrallServer) mustEmbedUnimplementedThrallServer() {}
+func (UnimplementedThrallServer) testEmbeddedByValue()                {}
+
+// UnsafeThrallServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ThrallServer will
+// result in compilation errors.
+type UnsafeThrallServer interface {
+	mustEmbedUnimplementedThrallServer()
+}
+
+func RegisterThrallServer(s grpc.ServiceRegistrar, srv ThrallServer) {
+	// If the following call panics, it indicates UnimplementedThrallServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Thrall_ServiceDesc, srv)
+}
+
+func _Thrall_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrallServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Thrall_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrallServer).Submit(ctx, req.(*SubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Thrall_SubmitBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrallServer).SubmitBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Thrall_SubmitBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrallServer).SubmitBatch(ctx, req.(*SubmitBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Thrall_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrallServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Thrall_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrallServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Thrall_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThrallServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Thrall_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThrallServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Thrall_WatchJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchJobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ThrallServer).WatchJob(m, &grpc.GenericServerStream[WatchJobRequest, JobStatus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Thrall_WatchJobServer = grpc.ServerStreamingServer[JobStatus]
+
+// Thrall_ServiceDesc is the grpc.ServiceDesc for Thrall service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Thrall_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "thrall.v1.Thrall",
+	HandlerType: (*ThrallServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _Thrall_Submit_Handler,
+		},
+		{
+			MethodName: "SubmitBatch",
+			Handler:    _Thrall_SubmitBatch_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Thrall_Cancel_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Thrall_GetStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchJob",
+			Handler:       _Thrall_WatchJob_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "thrall.proto",
+}
+
+const (
+	Workers_Lease_FullMethodName     = "/thrall.v1.Workers/Lease"
+	Workers_Heartbeat_FullMethodName = "/thrall.v1.Workers/Heartbeat"
+	Workers_Report_FullMethodName    = "/thrall.v1.Workers/Report"
+)
+
+// WorkersClient is the client API for Workers service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Workers leases thrall's jobs to remote workers, the jobs keep being
+// scheduled and limited by the thrall's workers pool that leases them.
+type WorkersClient interface {
+	// Lease leases the next job, it waits up to the requested wait for a job,
+	// the response job_id is empty if no job was ready.
+	Lease(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	// Heartbeat extends a leased job's lease, it reports if the job has been
+	// canceled.
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// Report reports a leased job's result, the error is empty if it succeed.
+	Report(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportResponse, error)
+}
+
+type workersClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorkersClient(cc grpc.ClientConnInterface) WorkersClient {
+	return &workersClient{cc}
+}
+
+func (c *workersClient) Lease(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, Workers_Lease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workersClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, Workers_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workersClient) Report(ctx context.Context, in *ReportRequest, opts ...grpc.CallOption) (*ReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportResponse)
+	err := c.cc.Invoke(ctx, Workers_Report_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkersServer is the server API for Workers service.
+// All implementations must embed UnimplementedWorkersServer
+// for forward compatibility.
+//
+// Workers leases thrall's jobs to remote workers, the jobs keep being
+// scheduled and limited by the thrall's workers pool that leases them.
+type WorkersServer interface {
+	// Lease leases the next job, it waits up to the requested wait for a job,
+	// the response job_id is empty if no job was ready.
+	Lease(context.Context, *LeaseRequest) (*LeaseResponse, error)
+	// Heartbeat extends a leased job's lease, it reports if the job has been
+	// canceled.
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// Report reports a leased job's result, the error is empty if it succeed.
+	Report(context.Context, *ReportRequest) (*ReportResponse, error)
+	mustEmbedUnimplementedWorkersServer()
+}
+
+// UnimplementedWorkersServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWorkersServer struct{}
+
+func (UnimplementedWorkersServer) Lease(context.Context, *LeaseRequest) (*LeaseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Lease not implemented")
+}
+func (UnimplementedWorkersServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedWorkersServer) Report(context.Context, *ReportRequest) (*ReportResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Report not implemented")
+}
+func (UnimplementedWorkersServer) mustEmbedUnimplementedWorkersServer() {}
+func (UnimplementedWorkersServer) testEmbeddedByValue()                 {}
+
+// UnsafeWorkersServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WorkersServer will
+// result in compilation errors.
+type UnsafeWorkersServer interface {
+	mustEmbedUnimplementedWorkersServer()
+}
+
+func RegisterWorkersServer(s grpc.ServiceRegistrar, srv WorkersServer) {
+	// If the following call panics, it indicates UnimplementedWorkersServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Workers_ServiceDesc, srv)
+}
+
+func _Workers_Lease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkersServer).Lease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workers_Lease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkersServer).Lease(ctx, req.(*LeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workers_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkersServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workers_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkersServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workers_Report_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkersServer).Report(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workers_Report_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkersServer).Report(ctx, req.(*ReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Workers_ServiceDesc is the grpc.ServiceDesc for Workers service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Workers_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "thrall.v1.Workers",
+	HandlerType: (*WorkersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lease",
+			Handler:    _Workers_Lease_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Workers_Heartbeat_Handler,
+		},
+		{
+			MethodName: "Report",
+			Handler:    _Workers_Report_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "thrall.proto",
+}
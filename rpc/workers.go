@@ -0,0 +1,311 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// The Workers service defaults.
+const (
+	defaultVisibility = 30 * time.Second
+	defaultLeaseWait  = 10 * time.Second
+	defaultRetryDelay = time.Second
+)
+
+// Coordinator is the Workers gRPC service implementation, it leases the jobs
+// of a thrall's workers pool initialized with thrall.WithRemoteWorkers.
+// Register it on a grpc.Server with RegisterWorkersServer.
+type Coordinator struct {
+	UnimplementedWorkersServer
+
+	// MaxWait is the longest that a Lease waits for a job, whatever the
+	// requested wait is.
+	MaxWait time.Duration
+}
+
+// NewCoordinator creates the Workers gRPC service.
+//
+// Returns the Coordinator.
+func NewCoordinator() *Coordinator {
+	return &Coordinator{MaxWait: time.Minute}
+}
+
+// Lease leases the next job, waiting up to the requested wait for it.
+func (s *Coordinator) Lease(ctx context.Context, req *LeaseRequest) (*LeaseResponse, error) {
+	if req.GetWorkerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "worker_id is required")
+	}
+
+	visibility := duration(req.GetVisibility(), defaultVisibility)
+
+	wait := duration(req.GetWait(), defaultLeaseWait)
+	if wait > s.MaxWait {
+		wait = s.MaxWait
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	job, err := thrall.LeaseJob(waitCtx, req.GetWorkerId(), visibility)
+	switch {
+	case err == nil:
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		return &LeaseResponse{}, nil
+	case errors.Is(err, thrall.ErrRemoteDisabled):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, thrall.ErrPoolClosed):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	default:
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &LeaseResponse{
+		JobId:    job.ID,
+		Token:    job.Token,
+		Job:      &Job{Type: job.Type, Payload: job.Payload},
+		Attempts: int32(job.Attempts),
+		Deadline: timestamp(job.Deadline),
+	}, nil
+}
+
+// Heartbeat extends a leased job's lease.
+func (s *Coordinator) Heartbeat(ctx context.Context, req *HeartbeatRequest) (*HeartbeatResponse, error) {
+	visibility := duration(req.GetVisibility(), defaultVisibility)
+
+	canceled, err := thrall.ExtendLease(req.GetJobId(), req.GetToken(), visibility)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "lease '%s' not found", req.GetJobId())
+	}
+
+	return &HeartbeatResponse{
+		Canceled: canceled,
+		Deadline: timestamp(time.Now().Add(visibility)),
+	}, nil
+}
+
+// Report reports a leased job's result.
+func (s *Coordinator) Report(ctx context.Context, req *ReportRequest) (*ReportResponse, error) {
+	var jobErr error
+	if req.GetError() != "" {
+		jobErr = errors.New(req.GetError())
+	}
+
+	if err := thrall.CompleteJob(req.GetJobId(), req.GetToken(), jobErr); err != nil {
+		return nil, status.Errorf(codes.NotFound, "lease '%s' not found", req.GetJobId())
+	}
+
+	return &ReportResponse{}, nil
+}
+
+// RemoteWorker runs the jobs leased from a Workers service, as thrall's
+// workers do, but on another process.
+type RemoteWorker struct {
+	// ID identifies the remote worker on the leasing thrall's logs and
+	// traces, it defaults to the hostname and pid.
+	ID string
+
+	// Codec decodes the leased jobs, it must match the leasing thrall's Codec.
+	Codec thrall.Codec
+
+	// Concurrency is the number of jobs run at once.
+	Concurrency int
+
+	// Visibility is the leased jobs lease, they are heartbeated on every half
+	// of it, and Wait the longest that a lease request waits for a job.
+	Visibility time.Duration
+	Wait       time.Duration
+
+	// OnError is called for every lease, heartbeat or report error, the job
+	// errors are reported to the leasing thrall instead.
+	OnError func(error)
+
+	client WorkersClient
+}
+
+// NewRemoteWorker creates a remote worker.
+//
+// - conn: The connection to the Workers service.
+// - types: The registry of the job types that can be leased.
+//
+// Returns the RemoteWorker, call it's Run to start running jobs.
+func NewRemoteWorker(conn grpc.ClientConnInterface, types *thrall.TypeRegistry) *RemoteWorker {
+	hostname, _ := os.Hostname()
+
+	return &RemoteWorker{
+		ID:          fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		Codec:       thrall.NewJSONCodec(types),
+		Concurrency: 1,
+		Visibility:  defaultVisibility,
+		Wait:        defaultLeaseWait,
+		client:      NewWorkersClient(conn),
+	}
+}
+
+// Run leases and runs jobs until the context is done, the running jobs are
+// finished and reported before returning.
+//
+// - ctx: The remote worker context.
+//
+// Returns nothing.
+func (rw *RemoteWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < rw.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				if !rw.runNext(ctx) {
+					select {
+					case <-ctx.Done():
+					case <-time.After(defaultRetryDelay):
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// runNext leases, runs and reports the next job.
+//
+// - ctx: The remote worker context.
+//
+// Returns false if the job couldn't be leased.
+func (rw *RemoteWorker) runNext(ctx context.Context) bool {
+	lease, err := rw.client.Lease(ctx, &LeaseRequest{
+		WorkerId:   rw.ID,
+		Visibility: durationpb.New(rw.Visibility),
+		Wait:       durationpb.New(rw.Wait),
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			rw.error(fmt.Errorf("job not leased. Err: %v", err))
+		}
+
+		return false
+	}
+
+	if lease.GetJobId() == "" {
+		return true
+	}
+
+	jobErr := rw.run(lease)
+
+	report := &ReportRequest{JobId: lease.GetJobId(), Token: lease.GetToken()}
+	if jobErr != nil {
+		report.Error = jobErr.Error()
+	}
+
+	// The job is reported even if the remote worker is being stopped.
+	if _, err := rw.client.Report(context.WithoutCancel(ctx), report); err != nil {
+		rw.error(fmt.Errorf("job '%s' not reported. Err: %v", lease.GetJobId(), err))
+	}
+
+	return true
+}
+
+// run decodes and runs a leased job, heartbeating it's lease while it runs.
+// The Contextual jobs context is canceled if the job is canceled or it's
+// lease is lost.
+//
+// - lease: The leased job.
+//
+// Returns the job's error.
+func (rw *RemoteWorker) run(lease *LeaseResponse) error {
+	job, err := rw.Codec.Decode(lease.GetJob().GetType(), lease.GetJob().GetPayload())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go rw.heartbeat(ctx, cancel, lease)
+
+	if contextual, ok := job.(thrall.Contextual); ok {
+		contextual.WithContext(ctx)
+	}
+
+	return job.Run()
+}
+
+// heartbeat extends a running job's lease on every half of the visibility,
+// until the job is done.
+//
+// - ctx: The running job's context.
+// - cancel: Cancels the running job's context.
+// - lease: The leased job.
+//
+// Returns nothing.
+func (rw *RemoteWorker) heartbeat(ctx context.Context, cancel context.CancelFunc, lease *LeaseResponse) {
+	id := lease.GetJobId()
+
+	ticker := time.NewTicker(rw.Visibility / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, err := rw.client.Heartbeat(ctx, &HeartbeatRequest{
+			JobId:      id,
+			Token:      lease.GetToken(),
+			Visibility: durationpb.New(rw.Visibility),
+		})
+
+		switch {
+		case status.Code(err) == codes.NotFound:
+			rw.error(fmt.Errorf("job '%s' lease lost", id))
+			cancel()
+			return
+		case err != nil:
+			if ctx.Err() == nil {
+				rw.error(fmt.Errorf("job '%s' not heartbeated. Err: %v", id, err))
+			}
+		case res.GetCanceled():
+			cancel()
+			return
+		}
+	}
+}
+
+// error reports a remote worker error to the OnError handler.
+//
+// - err: The remote worker error.
+//
+// Returns nothing.
+func (rw *RemoteWorker) error(err error) {
+	if rw.OnError != nil {
+		rw.OnError(err)
+	}
+}
+
+// duration converts a protobuf duration to a time.Duration.
+//
+// - d: The protobuf duration.
+// - fallback: The duration used if it's not set.
+//
+// Returns the duration.
+func duration(d *durationpb.Duration, fallback time.Duration) time.Duration {
+	if d == nil || d.AsDuration() <= 0 {
+		return fallback
+	}
+
+	return d.AsDuration()
+}
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRemoteWorker(t *testing.T) {
+	assert := assert.New(t)
+
+	types := newTestTypes()
+
+	_, _, close := thrall.Init(0, thrall.WithRemoteWorkers(thrall.NewJSONCodec(types)),
+		thrall.WithErrorsBuffer(10))
+	defer func() { close <- true }()
+
+	conn := newTestConn(t, func(server *grpc.Server) {
+		RegisterWorkersServer(server, NewCoordinator())
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rw := NewRemoteWorker(conn, types)
+	rw.ID = "remote-1"
+	rw.Concurrency = 2
+	rw.Visibility = 20 * time.Millisecond
+	rw.Wait = 10 * time.Millisecond
+
+	done := make(chan bool)
+	go func() {
+		rw.Run(ctx)
+		done <- true
+	}()
+
+	t.Run("when Run succeed running leased jobs", func(t *testing.T) {
+		ok, _ := thrall.Submit(&sleepJob{Millis: 50})
+		failed, _ := thrall.Submit(&sleepJob{Fail: true})
+
+		time.Sleep(100 * time.Millisecond)
+
+		js, _ := thrall.Status(ok)
+		assert.Equal(thrall.StateSucceeded, js.State)
+
+		js, _ = thrall.Status(failed)
+		assert.Equal(thrall.StateFailed, js.State)
+		assert.Equal("sleep failed", js.Error)
+	})
+
+	t.Run("when Run succeed stopping a canceled job", func(t *testing.T) {
+		id, _ := thrall.Submit(&sleepJob{Millis: 1000})
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Nil(thrall.Cancel(id))
+		time.Sleep(50 * time.Millisecond)
+
+		js, _ := thrall.Status(id)
+		assert.Equal(thrall.StateCanceled, js.State)
+	})
+
+	t.Run("when Heartbeat fails due an unknown lease", func(t *testing.T) {
+		client := NewWorkersClient(conn)
+
+		_, err := client.Heartbeat(ctx, &HeartbeatRequest{
+			JobId:      "foo",
+			Visibility: durationpb.New(time.Second),
+		})
+		assert.Equal(codes.NotFound, status.Code(err))
+
+		_, err = client.Report(ctx, &ReportRequest{JobId: "foo"})
+		assert.Equal(codes.NotFound, status.Code(err))
+	})
+
+	cancel()
+	<-done
+}
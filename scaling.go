@@ -0,0 +1,202 @@
+package thrall
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Autoscaler defines the rules to grow and shrink the workerPool workers at
+// runtime. The workerPool grows when jobs are waiting for a free worker
+// longer than MaxWait, and shrinks by one worker after every IdleTimeout
+// period with idle workers and no waiting jobs.
+type Autoscaler struct {
+	// Min and Max are the workers bounds.
+	Min int
+	Max int
+
+	// Interval is how often the workerPool usage is checked, 1 second by
+	// default.
+	Interval time.Duration
+
+	// MaxWait is the max time that a job should wait for a free worker, 100
+	// milliseconds by default.
+	MaxWait time.Duration
+
+	// IdleTimeout is the period with idle workers after which the workerPool
+	// shrinks, 30 seconds by default.
+	IdleTimeout time.Duration
+}
+
+// WithAutoscaler is an optional func for thrall's init, It does configure an
+// autoscaler that would resize the workerPool between the given bounds based
+// on the jobs queue depth and wait time. An autoscaler with invalid bounds,
+// as a Max lower than Min or a zero Min without remote workers, is logged and
+// ignored.
+//
+// - autoscaler: The autoscaler rules.
+//
+// Returns a optional configuration function.
+func WithAutoscaler(autoscaler Autoscaler) func(*workerPool) {
+	return func(wp *workerPool) {
+		if autoscaler.Interval == 0 {
+			autoscaler.Interval = time.Second
+		}
+
+		if autoscaler.MaxWait == 0 {
+			autoscaler.MaxWait = 100 * time.Millisecond
+		}
+
+		if autoscaler.IdleTimeout == 0 {
+			autoscaler.IdleTimeout = 30 * time.Second
+		}
+
+		wp.Autoscaler = &autoscaler
+	}
+}
+
+// validate checks the autoscaler workers bounds, the workerPool can be scaled
+// down to zero workers only if the remote workers are enabled.
+//
+// - remote: Whether the remote workers are enabled.
+//
+// Returns an error if the bounds are not valid.
+func (a *Autoscaler) validate(remote bool) error {
+	if a.Min < 0 || (a.Min == 0 && !remote) {
+		return fmt.Errorf("autoscaler min workers '%d' should be greater than zero", a.Min)
+	}
+
+	if a.Max < 1 || a.Max < a.Min {
+		return fmt.Errorf("autoscaler max workers '%d' should be greater than zero and min '%d'",
+			a.Max, a.Min)
+	}
+
+	return nil
+}
+
+// Resize sets thrall's number of workers, the new workers start accepting jobs
+// right away and the retired ones finish their current job before exiting.
+// It can be resized to zero workers only if the remote workers are enabled.
+//
+// - workers: The new number of workers.
+//
+// Returns an error if the number of workers is not valid.
+func Resize(workers int) error {
+	return wp.resize(workers)
+}
+
+// resize sets the workerPool number of workers.
+//
+// - n: The new number of workers.
+//
+// Returns an error if the number of workers is not valid.
+func (wp *workerPool) resize(n int) error {
+	if n < 0 || (n == 0 && wp.remoteLeases == nil) {
+		return errors.New("workers number should be greater than zero")
+	}
+
+	wp.workersMutex.Lock()
+	defer wp.workersMutex.Unlock()
+
+	current := len(wp.workers)
+
+	for len(wp.workers) < n {
+		worker := wp.newWorker()
+		wp.workers = append(wp.workers, worker)
+		worker.Start()
+	}
+
+	for len(wp.workers) > n {
+		last := len(wp.workers) - 1
+		close(wp.workers[last].retire)
+		wp.workers = wp.workers[:last]
+	}
+
+	if n != current {
+		wp.Logger.Info("workerpool resized", "pool", wp.Name, "from", current, "to", n)
+	}
+
+	return nil
+}
+
+// size returns the workerPool current number of workers.
+//
+// Returns the number of workers.
+func (wp *workerPool) size() int {
+	wp.workersMutex.Lock()
+	defer wp.workersMutex.Unlock()
+
+	return len(wp.workers)
+}
+
+// autoscale checks the workerPool usage on every Autoscaler interval and
+// resizes it until the workerPool is closed.
+//
+// Returns nothing.
+func (wp *workerPool) autoscale() {
+	ticker := time.NewTicker(wp.Autoscaler.Interval)
+	defer ticker.Stop()
+
+	idleSince := time.Now()
+	labels := map[string]string{"pool": wp.Name}
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-wp.workersClose:
+			return
+		}
+
+		size := wp.size()
+		queued := int(wp.queued.Load())
+		busy := int(wp.busy.Load())
+		wait := time.Duration(wp.maxWait.Swap(0))
+
+		if queued > 0 && (wait > wp.Autoscaler.MaxWait || queued >= size) &&
+			size < wp.Autoscaler.Max {
+			grow := queued
+			if size+grow > wp.Autoscaler.Max {
+				grow = wp.Autoscaler.Max - size
+			}
+
+			if err := wp.resize(size + grow); err != nil {
+				wp.Logger.Error("workerpool not scaled up", "pool", wp.Name, "error", err)
+				continue
+			}
+
+			wp.Metrics.IncWith(labels, "workerpool_scaled_up")
+			idleSince = time.Now()
+			continue
+		}
+
+		if queued > 0 || busy >= size {
+			idleSince = time.Now()
+			continue
+		}
+
+		if time.Since(idleSince) > wp.Autoscaler.IdleTimeout && size > wp.Autoscaler.Min {
+			if err := wp.resize(size - 1); err != nil {
+				wp.Logger.Error("workerpool not scaled down", "pool", wp.Name, "error", err)
+				continue
+			}
+
+			wp.Metrics.IncWith(labels, "workerpool_scaled_down")
+			idleSince = time.Now()
+		}
+	}
+}
+
+// observeWait keeps the longest time that a job have been waiting for a free
+// worker since the last autoscaler check.
+//
+// - wait: The job's wait time.
+//
+// Returns nothing.
+func (wp *workerPool) observeWait(wait time.Duration) {
+	for {
+		current := wp.maxWait.Load()
+		if int64(wait) <= current || wp.maxWait.CompareAndSwap(current, int64(wait)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package thrall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowJob struct {
+	testJob
+}
+
+func (sj *slowJob) Run() error {
+	time.Sleep(50 * time.Millisecond)
+	return sj.testJob.Run()
+}
+
+func TestResize(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Resize succeed adding and retiring workers", func(t *testing.T) {
+		queue, _, close := Init(1)
+
+		assert.Nil(Resize(3))
+		assert.Equal(3, wp.size())
+		assert.Equal(3, wp.workers[2].Id)
+
+		assert.Nil(Resize(2))
+		assert.Equal(2, wp.size())
+
+		var job testJob
+		queue <- &job
+		time.Sleep(10 * time.Millisecond)
+		assert.True(job.Executed.Load())
+
+		close <- true
+	})
+
+	t.Run("when Resize fails due an invalid number of workers", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		err := Resize(0)
+		assert.Equal("workers number should be greater than zero", err.Error())
+		assert.Equal(1, wp.size())
+
+		close <- true
+	})
+
+	t.Run("when Resize succeed retiring every worker with remote workers", func(t *testing.T) {
+		_, _, close := Init(1, WithRemoteWorkers(NewJSONCodec(newTestTypes())))
+
+		assert.Nil(Resize(0))
+		assert.Equal(0, wp.size())
+
+		close <- true
+	})
+}
+
+func TestWithAutoscaler(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when the autoscaler succeed growing and shrinking the workers", func(t *testing.T) {
+		queue, _, close := Init(1, WithAutoscaler(Autoscaler{
+			Min:         1,
+			Max:         4,
+			Interval:    10 * time.Millisecond,
+			MaxWait:     time.Millisecond,
+			IdleTimeout: 30 * time.Millisecond,
+		}))
+
+		go func() {
+			for i := 0; i < 8; i++ {
+				queue <- &slowJob{}
+			}
+		}()
+
+		time.Sleep(60 * time.Millisecond)
+		assert.True(wp.size() > 1)
+
+		time.Sleep(500 * time.Millisecond)
+		assert.Equal(1, wp.size())
+
+		close <- true
+	})
+
+	t.Run("when the initial workers are bounded by the autoscaler", func(t *testing.T) {
+		_, _, close := Init(10, WithAutoscaler(Autoscaler{Min: 1, Max: 2}))
+
+		assert.Equal(2, wp.size())
+
+		close <- true
+	})
+
+	t.Run("when the autoscaler is ignored due invalid bounds", func(t *testing.T) {
+		_, _, close := Init(1, WithAutoscaler(Autoscaler{Min: 2}))
+
+		assert.Nil(wp.Autoscaler)
+		assert.Equal(1, wp.size())
+
+		close <- true
+	})
+
+	t.Run("when the autoscaler is ignored due a zero min without remote workers", func(t *testing.T) {
+		_, _, close := Init(1, WithAutoscaler(Autoscaler{Min: 0, Max: 2}))
+
+		assert.Nil(wp.Autoscaler)
+		assert.Equal(1, wp.size())
+
+		close <- true
+	})
+
+	t.Run("when the autoscaler succeed with a zero min and remote workers", func(t *testing.T) {
+		_, _, close := Init(0, WithAutoscaler(Autoscaler{Min: 0, Max: 2}),
+			WithRemoteWorkers(NewJSONCodec(newTestTypes())))
+
+		assert.NotNil(wp.Autoscaler)
+		assert.Equal(0, wp.size())
+
+		close <- true
+	})
+}
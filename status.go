@@ -0,0 +1,749 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jcleira/thrall/limiters"
+)
+
+// defaultStatusHistory is the default number of finished jobs statuses and
+// dead letters kept.
+const defaultStatusHistory = 1000
+
+// defaultSubmitBuffer is the default number of submitted jobs waiting to be
+// received.
+const defaultSubmitBuffer = 1024
+
+// ErrQueueFull is returned by Submit when the submitted jobs queue is full.
+var ErrQueueFull = errors.New("submit queue full")
+
+// JobState is a job's lifecycle state.
+type JobState string
+
+// The jobs lifecycle states.
+const (
+	StateQueued    JobState = "queued"
+	StateScheduled JobState = "scheduled"
+	StateRunning   JobState = "running"
+	StateSucceeded JobState = "succeeded"
+	StateFailed    JobState = "failed"
+	StateDiscarded JobState = "discarded"
+	StateCanceled  JobState = "canceled"
+
+	// StateSkipped is the state of the workflow nodes that are not run, as
+	// their dependencies failed.
+	StateSkipped JobState = "skipped"
+)
+
+// Finished returns true for the final states, once a job reaches them it
+// won't change it's state anymore.
+//
+// Returns true if the state is final.
+func (s JobState) Finished() bool {
+	switch s {
+	case StateSucceeded, StateFailed, StateDiscarded, StateCanceled, StateSkipped:
+		return true
+	}
+
+	return false
+}
+
+// JobStatus is a job's current status as seen by this process.
+type JobStatus struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	State    JobState  `json:"state"`
+	Enqueued time.Time `json:"enqueued"`
+	Schedule time.Time `json:"schedule,omitempty"`
+	Started  time.Time `json:"started,omitempty"`
+	Finished time.Time `json:"finished,omitempty"`
+	Attempts int       `json:"attempts"`
+
+	// Worker is the worker running the job, or the one that run it last, the
+	// local workers by their ID and the remote workers by their remote ID.
+	Worker string `json:"worker,omitempty"`
+
+	// Error is the last run's error message, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// PoolStats is a snapshot of thrall's workers and jobs queue depth.
+type PoolStats struct {
+	Name    string `json:"name"`
+	Workers int    `json:"workers"`
+	Busy    int    `json:"busy"`
+	Paused  bool   `json:"paused"`
+
+	// Queued are the jobs waiting for a free worker, Held the jobs held by a
+	// pause and Scheduled the jobs waiting for their execution time.
+	Queued    int `json:"queued"`
+	Held      int `json:"held"`
+	Scheduled int `json:"scheduled"`
+
+	// PausedTypes are the paused job types and Limiter the configured
+	// limiter's utilization.
+	PausedTypes []string       `json:"paused_types"`
+	Limiter     limiters.Stats `json:"limiter"`
+}
+
+// deadLetter is a failed job that won't be retried, kept with it's status so
+// it can be retried by hand.
+type deadLetter struct {
+	status JobStatus
+	job    Runnable
+}
+
+// WithStatusHistory is an optional func for thrall's init, It does configure
+// how many finished jobs statuses, and how many dead letters, are kept to be
+// inspected. It defaults to 1000.
+//
+// - size: The number of finished jobs statuses kept.
+//
+// Returns a optional configuration function.
+func WithStatusHistory(size int) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.statusHistory = size
+	}
+}
+
+// WithSubmitBuffer is an optional func for thrall's init, It does configure
+// how many submitted jobs can wait to be received, Submit returns
+// ErrQueueFull once they are waiting. It defaults to 1024.
+//
+// - size: The submitted jobs queue size.
+//
+// Returns a optional configuration function.
+func WithSubmitBuffer(size int) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.submitBuffer = size
+	}
+}
+
+// Submit sends a job to thrall as sending it to the jobs channel does, but
+// without blocking until a worker takes it. The submitted jobs are received
+// in their submission order.
+//
+// - job: The job to run.
+//
+// Returns the job ID to check it's status, the duplicate's ID if it's a Unique
+// job coalesced into it, ErrDuplicateJob if it's a rejected Unique job,
+// ErrQueueFull if too many submitted jobs are waiting or an error if thrall is
+// closed.
+func Submit(job Runnable) (string, error) {
+	return wp.submit(job)
+}
+
+// Status returns a job's status, the finished jobs statuses are kept up to
+// the status history size.
+//
+// - id: The job ID.
+//
+// Returns the job's status and whether it's known.
+func Status(id string) (JobStatus, bool) {
+	return wp.status(id)
+}
+
+// WatchStatus calls the watcher with a job's status, then on every change of
+// it's status, in order, until the job is finished, it blocks meanwhile.
+//
+// - ctx: The watch's context.
+// - id: The job ID.
+// - watcher: The func called with the job's statuses, the watch stops if it
+// returns an error.
+//
+// Returns nil once the job is finished, ErrJobNotFound if the job is unknown
+// or it's status is dropped before it's finished, the context's error if it's
+// canceled or the watcher's error.
+func WatchStatus(ctx context.Context, id string, watcher func(JobStatus) error) error {
+	return wp.watchStatus(ctx, id, watcher)
+}
+
+// Stats returns thrall's workers and jobs queue depth.
+//
+// Returns the pool stats.
+func Stats() PoolStats {
+	return wp.stats()
+}
+
+// Scheduled returns the scheduled jobs statuses sorted by their execution
+// time.
+//
+// Returns the scheduled jobs statuses.
+func Scheduled() []JobStatus {
+	return wp.scheduled()
+}
+
+// DeadLetters returns the statuses of the jobs that failed and won't be
+// retried, the newest first.
+//
+// Returns the dead letters.
+func DeadLetters() []JobStatus {
+	return wp.deadLetters()
+}
+
+// Running returns the running jobs statuses, with the worker running them,
+// sorted by their start time.
+//
+// Returns the running jobs statuses.
+func Running() []JobStatus {
+	return wp.running()
+}
+
+// Failures returns the statuses of the jobs which last run failed, including
+// those that would be retried, the most recent failure first.
+//
+// Returns the failed jobs statuses.
+func Failures() []JobStatus {
+	return wp.failures()
+}
+
+// submit receives a job without blocking the caller.
+//
+// - job: The job to run.
+//
+// Returns the job ID, or an error if the job is a rejected duplicate, the
+// submitted jobs queue is full or the workerPool is closed.
+func (wp *workerPool) submit(job Runnable) (string, error) {
+	select {
+	case <-wp.workersClose:
+		return "", ErrPoolClosed
+	default:
+	}
+
+	e := wp.newEnvelope(job)
+	if id, err := wp.unique(e); err != nil || id != e.id {
+		return id, err
+	}
+
+	if id := wp.delay(e); id != e.id {
+		return id, nil
+	}
+
+	wp.track(e)
+
+	select {
+	case wp.submitted <- e:
+	default:
+		wp.rejectSubmitted(e)
+		return "", ErrQueueFull
+	}
+
+	return e.id, nil
+}
+
+// rejectSubmitted drops a submitted job that doesn't fit on the submitted
+// jobs queue, releasing it's unique and delayed keys.
+//
+// - e: The rejected enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) rejectSubmitted(e *envelope) {
+	wp.statusMutex.Lock()
+	delete(wp.statuses, e.id)
+	wp.dropChanges(e.id)
+	wp.statusMutex.Unlock()
+
+	wp.releaseUnique(e)
+	wp.releaseDelayed(e)
+	wp.endPhase(e)
+	wp.IncMetric(e.job, "workerpool_job_rejected")
+	wp.Logger.Warn("job rejected", jobFields(e, "error", ErrQueueFull)...)
+}
+
+// receiveSubmitted receives the submitted jobs in their submission order
+// until the workerPool is closed, then discards the ones still waiting.
+//
+// Returns nothing.
+func (wp *workerPool) receiveSubmitted() {
+	for {
+		select {
+		case e := <-wp.submitted:
+			wp.receive(e)
+		case <-wp.workersClose:
+			for {
+				select {
+				case e := <-wp.submitted:
+					wp.discard(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// track starts tracking a received job's status.
+//
+// - e: The received enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) track(e *envelope) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	if _, exists := wp.statuses[e.id]; exists {
+		return
+	}
+
+	status := &JobStatus{
+		ID:       e.id,
+		Type:     jobType(e.job),
+		State:    StateQueued,
+		Enqueued: time.Now(),
+		Attempts: e.attempts,
+	}
+
+	if scheduleable, ok := e.job.(Scheduleable); ok {
+		status.State = StateScheduled
+		status.Schedule = scheduleable.Schedule()
+	}
+
+	if !e.due.IsZero() {
+		status.State = StateScheduled
+		status.Schedule = e.due
+	}
+
+	wp.statuses[e.id] = status
+}
+
+// updateStatus updates a tracked job's status.
+//
+// - e: The enveloped job.
+// - update: The status update func.
+//
+// Returns nothing.
+func (wp *workerPool) updateStatus(e *envelope, update func(*JobStatus)) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	status, exists := wp.statuses[e.id]
+	if !exists {
+		return
+	}
+
+	previous := *status
+	update(status)
+
+	if *status != previous {
+		wp.publishStatus(*status)
+	}
+
+	if !status.State.Finished() {
+		return
+	}
+
+	delete(wp.canceled, e.id)
+	wp.releaseUnique(e)
+	wp.releaseDelayed(e)
+
+	for _, watcher := range wp.watchers[e.id] {
+		go watcher(*status)
+	}
+	delete(wp.watchers, e.id)
+	wp.finished = append(wp.finished, e.id)
+	if status.State == StateFailed {
+		wp.dead = append(wp.dead, deadLetter{status: *status, job: e.job})
+	}
+
+	wp.trimStatuses()
+}
+
+// trimStatuses drops the oldest finished jobs statuses and dead letters over
+// the status history size.
+//
+// Returns nothing.
+func (wp *workerPool) trimStatuses() {
+	for len(wp.finished) > wp.statusHistory {
+		id := wp.finished[0]
+		wp.finished = wp.finished[1:]
+
+		if status, exists := wp.statuses[id]; exists && status.State.Finished() {
+			delete(wp.statuses, id)
+		}
+	}
+
+	if len(wp.dead) > wp.statusHistory {
+		wp.dead = wp.dead[len(wp.dead)-wp.statusHistory:]
+	}
+}
+
+// startStatus sets a job as running.
+//
+// - e: The running enveloped job.
+// - worker: The worker running the job.
+//
+// Returns nothing.
+func (wp *workerPool) startStatus(e *envelope, worker string) {
+	wp.updateStatus(e, func(status *JobStatus) {
+		status.State = StateRunning
+		status.Started = time.Now()
+		status.Worker = worker
+		status.Attempts++
+		if e.stored {
+			status.Attempts = e.attempts
+		}
+	})
+}
+
+// finishStatus sets a job as succeeded, failed or canceled, the failed jobs
+// that would be retried are set as queued.
+//
+// - e: The done enveloped job.
+// - err: The job's error, nil if it succeed.
+// - retry: Whether the failed job would be retried.
+//
+// Returns true if the job has been canceled while running.
+func (wp *workerPool) finishStatus(e *envelope, err error, retry bool) bool {
+	canceled := false
+
+	wp.updateStatus(e, func(status *JobStatus) {
+		status.Finished = time.Now()
+
+		switch {
+		case wp.canceled[e.id]:
+			canceled = true
+			status.State = StateCanceled
+		case err == nil:
+			status.State = StateSucceeded
+		case retry:
+			status.State = StateQueued
+			status.Error = err.Error()
+		default:
+			status.State = StateFailed
+			status.Error = err.Error()
+		}
+	})
+
+	return canceled
+}
+
+// discardStatus sets a job as discarded.
+//
+// - e: The discarded enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) discardStatus(e *envelope) {
+	wp.updateStatus(e, func(status *JobStatus) {
+		status.State = StateDiscarded
+		status.Finished = time.Now()
+	})
+}
+
+// watch calls the watcher once a job is finished, right away if it already
+// is. The watcher is called on it's own goroutine.
+//
+// - id: The job ID.
+// - watcher: The func called with the job's final status.
+//
+// Returns false if the job is unknown.
+func (wp *workerPool) watch(id string, watcher func(JobStatus)) bool {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	status, exists := wp.statuses[id]
+	if !exists {
+		return false
+	}
+
+	if status.State.Finished() {
+		go watcher(*status)
+		return true
+	}
+
+	wp.watchers[id] = append(wp.watchers[id], watcher)
+
+	return true
+}
+
+// statusChanges are a watched job's status changes waiting for it's watcher,
+// the changes are queued so none is lost while the watcher is busy.
+type statusChanges struct {
+	pending []JobStatus
+	dropped bool
+	notify  chan bool
+}
+
+// watchStatus calls the watcher with a job's status and it's changes until
+// the job is finished.
+//
+// - ctx: The watch's context.
+// - id: The job ID.
+// - watcher: The func called with the job's statuses.
+//
+// Returns nil once the job is finished, or the watch's error.
+func (wp *workerPool) watchStatus(ctx context.Context, id string,
+	watcher func(JobStatus) error) error {
+	changes, ok := wp.subscribeStatus(id)
+	if !ok {
+		return ErrJobNotFound
+	}
+	defer wp.unsubscribeStatus(id, changes)
+
+	for {
+		pending, dropped := wp.pendingChanges(changes)
+
+		for _, status := range pending {
+			if err := watcher(status); err != nil {
+				return err
+			}
+
+			if status.State.Finished() {
+				return nil
+			}
+		}
+
+		if dropped {
+			return ErrJobNotFound
+		}
+
+		select {
+		case <-changes.notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// subscribeStatus starts queueing a job's status changes, the job's current
+// status being the first one.
+//
+// - id: The job ID.
+//
+// Returns the job's status changes, and false if the job is unknown.
+func (wp *workerPool) subscribeStatus(id string) (*statusChanges, bool) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	status, exists := wp.statuses[id]
+	if !exists {
+		return nil, false
+	}
+
+	changes := &statusChanges{
+		pending: []JobStatus{*status},
+		notify:  make(chan bool, 1),
+	}
+	wp.changes[id] = append(wp.changes[id], changes)
+
+	return changes, true
+}
+
+// unsubscribeStatus stops queueing a job's status changes.
+//
+// - id: The job ID.
+// - changes: The job's status changes to stop.
+//
+// Returns nothing.
+func (wp *workerPool) unsubscribeStatus(id string, changes *statusChanges) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	subscribed := wp.changes[id]
+	for i, c := range subscribed {
+		if c == changes {
+			subscribed = append(subscribed[:i], subscribed[i+1:]...)
+			break
+		}
+	}
+
+	if len(subscribed) == 0 {
+		delete(wp.changes, id)
+		return
+	}
+
+	wp.changes[id] = subscribed
+}
+
+// pendingChanges takes the status changes queued for a watcher.
+//
+// - changes: The job's status changes.
+//
+// Returns the queued changes, and true if the job's status has been dropped.
+func (wp *workerPool) pendingChanges(changes *statusChanges) ([]JobStatus, bool) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	pending := changes.pending
+	changes.pending = nil
+
+	return pending, changes.dropped
+}
+
+// publishStatus queues a job's status change for it's watchers, the caller
+// must hold the statusMutex.
+//
+// - status: The job's changed status.
+//
+// Returns nothing.
+func (wp *workerPool) publishStatus(status JobStatus) {
+	for _, changes := range wp.changes[status.ID] {
+		changes.pending = append(changes.pending, status)
+		changes.wake()
+	}
+}
+
+// dropChanges tells a job's watchers that it's status has been dropped, the
+// caller must hold the statusMutex.
+//
+// - id: The job ID.
+//
+// Returns nothing.
+func (wp *workerPool) dropChanges(id string) {
+	for _, changes := range wp.changes[id] {
+		changes.dropped = true
+		changes.wake()
+	}
+}
+
+// wake wakes up the status changes watcher.
+//
+// Returns nothing.
+func (sc *statusChanges) wake() {
+	select {
+	case sc.notify <- true:
+	default:
+	}
+}
+
+// status returns a job's status.
+//
+// - id: The job ID.
+//
+// Returns the job's status and whether it's known.
+func (wp *workerPool) status(id string) (JobStatus, bool) {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	status, exists := wp.statuses[id]
+	if !exists {
+		return JobStatus{}, false
+	}
+
+	return *status, true
+}
+
+// stats returns the workerPool stats.
+//
+// Returns the workerPool stats.
+func (wp *workerPool) stats() PoolStats {
+	stats := PoolStats{
+		Name:    wp.Name,
+		Workers: wp.size(),
+		Busy:    int(wp.busy.Load()),
+		Queued:  int(wp.queued.Load()),
+	}
+
+	wp.pauseMutex.Lock()
+	stats.Paused = wp.paused
+	stats.Held = len(wp.held)
+	stats.PausedTypes = make([]string, 0, len(wp.pausedTypes))
+	for t := range wp.pausedTypes {
+		stats.PausedTypes = append(stats.PausedTypes, t)
+	}
+	wp.pauseMutex.Unlock()
+
+	sort.Strings(stats.PausedTypes)
+	stats.Limiter = wp.Limiter.Stats()
+
+	wp.DelayedMutext.Lock()
+	for _, envelopes := range wp.Delayed {
+		stats.Scheduled += len(envelopes)
+	}
+	wp.DelayedMutext.Unlock()
+
+	return stats
+}
+
+// scheduled returns the scheduled jobs statuses.
+//
+// Returns the scheduled jobs statuses sorted by their execution time.
+func (wp *workerPool) scheduled() []JobStatus {
+	wp.DelayedMutext.Lock()
+	var ids []string
+	for _, envelopes := range wp.Delayed {
+		for _, e := range envelopes {
+			ids = append(ids, e.id)
+		}
+	}
+	wp.DelayedMutext.Unlock()
+
+	scheduled := []JobStatus{}
+	for _, id := range ids {
+		if status, exists := wp.status(id); exists {
+			scheduled = append(scheduled, status)
+		}
+	}
+
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].Schedule.Before(scheduled[j].Schedule)
+	})
+
+	return scheduled
+}
+
+// deadLetters returns the dead letters.
+//
+// Returns the dead letters, the newest first.
+func (wp *workerPool) deadLetters() []JobStatus {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	dead := make([]JobStatus, 0, len(wp.dead))
+	for i := len(wp.dead) - 1; i >= 0; i-- {
+		dead = append(dead, wp.dead[i].status)
+	}
+
+	return dead
+}
+
+// running returns the running jobs statuses.
+//
+// Returns the running jobs statuses sorted by their start time.
+func (wp *workerPool) running() []JobStatus {
+	running := wp.filterStatuses(func(status *JobStatus) bool {
+		return status.State == StateRunning
+	})
+
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].Started.Before(running[j].Started)
+	})
+
+	return running
+}
+
+// failures returns the statuses of the jobs which last run failed.
+//
+// Returns the failed jobs statuses, the most recent failure first.
+func (wp *workerPool) failures() []JobStatus {
+	failures := wp.filterStatuses(func(status *JobStatus) bool {
+		return status.Error != "" && status.State != StateRunning
+	})
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Finished.After(failures[j].Finished)
+	})
+
+	return failures
+}
+
+// filterStatuses returns the tracked jobs statuses that match a filter.
+//
+// - match: The filter func.
+//
+// Returns the matching jobs statuses.
+func (wp *workerPool) filterStatuses(match func(*JobStatus) bool) []JobStatus {
+	wp.statusMutex.Lock()
+	defer wp.statusMutex.Unlock()
+
+	statuses := []JobStatus{}
+	for _, status := range wp.statuses {
+		if match(status) {
+			statuses = append(statuses, *status)
+		}
+	}
+
+	return statuses
+}
@@ -0,0 +1,236 @@
+package thrall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderedJob struct {
+	testJob
+	n     int
+	order chan int
+}
+
+func (oj *orderedJob) Run() error {
+	oj.order <- oj.n
+	return oj.testJob.Run()
+}
+
+func TestStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Submit succeed tracking a job's status", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		id, err := Submit(&testJob{})
+		assert.Nil(err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		status, exists := Status(id)
+		assert.True(exists)
+		assert.Equal(StateSucceeded, status.State)
+		assert.Equal("testJob", status.Type)
+		assert.Equal(1, status.Attempts)
+		assert.False(status.Finished.IsZero())
+
+		close <- true
+	})
+
+	t.Run("when a failed job succeed being kept as a dead letter", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(1))
+
+		id, err := Submit(&errorJob{})
+		assert.Nil(err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		dead := DeadLetters()
+		assert.Len(dead, 1)
+		assert.Equal(id, dead[0].ID)
+		assert.Equal(StateFailed, dead[0].State)
+		assert.Equal("error!", dead[0].Error)
+
+		close <- true
+	})
+
+	t.Run("when Running succeed returning the running jobs and their worker", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(2))
+
+		id, _ := Submit(&slowJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		running := Running()
+		assert.Len(running, 1)
+		assert.Equal(id, running[0].ID)
+		assert.Equal("1", running[0].Worker)
+		assert.Equal(1, Stats().Busy)
+		assert.Equal("max", Stats().Limiter.Name)
+		assert.Equal(1, Stats().Limiter.InUse)
+
+		close <- true
+	})
+
+	t.Run("when Failures succeed returning the failed jobs", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(2))
+
+		first, _ := Submit(&errorJob{})
+		time.Sleep(10 * time.Millisecond)
+		second, _ := Submit(&errorJob{})
+		Submit(&testJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		failures := Failures()
+		assert.Len(failures, 2)
+		assert.Equal(second, failures[0].ID)
+		assert.Equal(first, failures[1].ID)
+
+		close <- true
+	})
+
+	t.Run("when a scheduled job succeed being discarded on close", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		id, err := Submit(&scheduleableJob{})
+		assert.Nil(err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		scheduled := Scheduled()
+		assert.Len(scheduled, 1)
+		assert.Equal(id, scheduled[0].ID)
+		assert.Equal(1, Stats().Scheduled)
+
+		close <- true
+		time.Sleep(10 * time.Millisecond)
+
+		status, _ := Status(id)
+		assert.Equal(StateDiscarded, status.State)
+	})
+
+	t.Run("when WithStatusHistory succeed dropping the oldest statuses", func(t *testing.T) {
+		_, _, close := Init(1, WithStatusHistory(1))
+
+		first, _ := Submit(&testJob{})
+		time.Sleep(10 * time.Millisecond)
+		second, _ := Submit(&testJob{})
+		time.Sleep(10 * time.Millisecond)
+
+		_, exists := Status(first)
+		assert.False(exists)
+
+		_, exists = Status(second)
+		assert.True(exists)
+
+		close <- true
+	})
+
+	t.Run("when Submit succeed receiving the jobs in their submission order", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		order := make(chan int, 10)
+		for i := 0; i < 10; i++ {
+			_, err := Submit(&orderedJob{n: i, order: order})
+			assert.Nil(err)
+		}
+
+		for i := 0; i < 10; i++ {
+			assert.Equal(i, <-order)
+		}
+
+		close <- true
+	})
+
+	t.Run("when Submit fails due a full submit queue", func(t *testing.T) {
+		_, _, close := Init(1, WithSubmitBuffer(1))
+
+		release := make(chan struct{})
+		_, err := Submit(&blockingJob{release: release})
+		assert.Nil(err)
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = Submit(&testJob{})
+		assert.Nil(err)
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = Submit(&testJob{})
+		assert.Nil(err)
+
+		id, err := Submit(&testJob{})
+		assert.Equal(ErrQueueFull, err)
+		assert.Empty(id)
+		assert.Len(Failures(), 0)
+
+		release <- struct{}{}
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = Submit(&testJob{})
+		assert.Nil(err)
+
+		close <- true
+	})
+
+	t.Run("when WatchStatus succeed calling the watcher on every status change", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		release := make(chan struct{})
+		_, err := Submit(&blockingJob{release: release})
+		assert.Nil(err)
+
+		id, err := Submit(&testJob{})
+		assert.Nil(err)
+		time.Sleep(10 * time.Millisecond)
+
+		done := make(chan error)
+		var states []JobState
+		go func() {
+			done <- WatchStatus(context.Background(), id, func(status JobStatus) error {
+				states = append(states, status.State)
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+		release <- struct{}{}
+
+		assert.Nil(<-done)
+		assert.Equal([]JobState{StateQueued, StateRunning, StateSucceeded}, states)
+
+		close <- true
+	})
+
+	t.Run("when WatchStatus fails due an unknown or dropped job", func(t *testing.T) {
+		_, _, close := Init(1)
+
+		err := WatchStatus(context.Background(), "foo", func(JobStatus) error { return nil })
+		assert.Equal(ErrJobNotFound, err)
+
+		e := &envelope{id: "1", job: &testJob{}}
+		wp.track(e)
+
+		done := make(chan error)
+		go func() {
+			done <- WatchStatus(context.Background(), "1", func(JobStatus) error { return nil })
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+		wp.rejectSubmitted(e)
+
+		assert.Equal(ErrJobNotFound, <-done)
+
+		close <- true
+	})
+
+	t.Run("when Submit fails due a closed thrall", func(t *testing.T) {
+		_, _, close := Init(1)
+		close <- true
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := Submit(&testJob{})
+		assert.Equal("workerpool closed", err.Error())
+	})
+}
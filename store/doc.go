@@ -0,0 +1,11 @@
+/*
+Package store provides durable Job storage for thrall's workers.
+
+It does define the Store interface that any persistent backend should
+implement, the workerPool enqueues every received job on the Store, then it
+reserves the jobs to run them and acks them once they are done. A FileStore,
+an append-only log file with compaction, a SQLStore, for any database/sql
+database as PostgreSQL, MySQL or SQLite, and a RedisStore, a queue shared by
+many processes, are provided.
+*/
+package store
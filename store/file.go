@@ -0,0 +1,524 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The FileStore log record operations.
+const (
+	opEnqueue = "enqueue"
+	opReserve = "reserve"
+	opAck     = "ack"
+	opNack    = "nack"
+	opExtend  = "extend"
+	opRemove  = "remove"
+	opBury    = "bury"
+	opRevive  = "revive"
+)
+
+// record is a single FileStore log line.
+type record struct {
+	Op    string    `json:"op"`
+	ID    string    `json:"id,omitempty"`
+	Job   *Job      `json:"job,omitempty"`
+	Lease time.Time `json:"lease,omitempty"`
+	Token string    `json:"token,omitempty"`
+
+	// Schedule is the nack record's retry time, zero if the job is ready
+	// right away.
+	Schedule time.Time `json:"schedule,omitempty"`
+
+	// Buried and Error are the bury record's time and job error.
+	Buried time.Time `json:"buried,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// FileStore is a Store backed by an append-only log file, every operation is
+// appended and synced to the log, and the log is replayed on open. The log is
+// compacted, rewritten with only the stored jobs, once it grows over
+// CompactThreshold records per stored job.
+type FileStore struct {
+	// CompactThreshold is the log records per stored job ratio that triggers
+	// the log compaction.
+	CompactThreshold int
+
+	path    string
+	file    *os.File
+	jobs    map[string]*Job
+	seqs    map[string]int
+	seq     int
+	records int
+
+	sync.Mutex
+}
+
+// NewFileStore opens or creates a FileStore on the given path. The jobs that
+// were reserved when the store was closed are returned as ready, as the
+// process that reserved them is gone.
+//
+// - path: The log file path.
+//
+// Returns the FileStore or an error if the log can't be opened or replayed.
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{
+		CompactThreshold: 4,
+		path:             path,
+		jobs:             make(map[string]*Job),
+		seqs:             make(map[string]int),
+	}
+
+	if err := fs.replay(); err != nil {
+		return nil, err
+	}
+
+	for _, job := range fs.jobs {
+		job.Reserved = false
+		job.Lease = time.Time{}
+		job.Token = ""
+	}
+
+	if err := fs.compact(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// Enqueue stores a job ready to be reserved.
+//
+// - job: The job to store.
+//
+// Returns an error if the job can't be stored.
+func (fs *FileStore) Enqueue(job *Job) error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	if _, exists := fs.jobs[job.ID]; exists {
+		return fmt.Errorf("job '%s' already stored", job.ID)
+	}
+
+	if job.Enqueued.IsZero() {
+		job.Enqueued = time.Now()
+	}
+
+	stored := *job
+	if err := fs.append(record{Op: opEnqueue, Job: &stored}); err != nil {
+		return err
+	}
+
+	fs.apply(record{Op: opEnqueue, Job: &stored})
+
+	return nil
+}
+
+// Schedule stores a job that would be ready to be reserved at when.
+//
+// - job: The job to store.
+// - when: The job programmed execution time.
+//
+// Returns an error if the job can't be stored.
+func (fs *FileStore) Schedule(job *Job, when time.Time) error {
+	job.Schedule = when
+	return fs.Enqueue(job)
+}
+
+// Reserve reserves the oldest ready job, the reserved jobs with an expired
+// lease are ready too.
+//
+// - visibility: The reservation's visibility timeout.
+//
+// Returns the reserved job, ErrNoJobs if none is ready.
+func (fs *FileStore) Reserve(visibility time.Duration) (*Job, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	now := time.Now()
+	for _, job := range fs.sorted() {
+		if !job.Ready(now) {
+			continue
+		}
+
+		r := record{Op: opReserve, ID: job.ID, Lease: now.Add(visibility), Token: newToken()}
+		if err := fs.append(r); err != nil {
+			return nil, err
+		}
+
+		fs.apply(r)
+
+		reserved := *job
+		return &reserved, nil
+	}
+
+	return nil, ErrNoJobs
+}
+
+// Extend extends a reserved job's lease.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - visibility: The visibility timeout to extend the lease by, from now.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (fs *FileStore) Extend(id, token string, visibility time.Duration) error {
+	r := record{Op: opExtend, ID: id, Lease: time.Now().Add(visibility)}
+
+	return fs.write(r, "not reserved", reservedBy(token))
+}
+
+// Ack removes a reserved job from the store.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (fs *FileStore) Ack(id, token string) error {
+	return fs.write(record{Op: opAck, ID: id}, "not reserved", reservedBy(token))
+}
+
+// Nack returns a reserved job to the store, ready once the delay has passed.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - delay: The time to wait before the job is ready, zero for right away.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (fs *FileStore) Nack(id, token string, delay time.Duration) error {
+	r := record{Op: opNack, ID: id}
+	if delay > 0 {
+		r.Schedule = time.Now().Add(delay)
+	}
+
+	return fs.write(r, "not reserved", reservedBy(token))
+}
+
+// List returns all the stored jobs sorted by their enqueue order, but the
+// dead letters.
+//
+// Returns the stored jobs.
+func (fs *FileStore) List() ([]*Job, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	jobs := []*Job{}
+	for _, job := range fs.sorted() {
+		if job.Buried.IsZero() {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+
+	return jobs, nil
+}
+
+// Remove removes a job that is not reserved.
+//
+// - id: The job ID.
+//
+// Returns an error if the job is not queued or scheduled.
+func (fs *FileStore) Remove(id string) error {
+	return fs.write(record{Op: opRemove, ID: id}, "not queued", func(job *Job) bool {
+		return !job.Reserved && job.Buried.IsZero()
+	})
+}
+
+// Bury moves a reserved job to the dead letters.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - reason: The job's last error.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (fs *FileStore) Bury(id, token, reason string) error {
+	r := record{Op: opBury, ID: id, Buried: time.Now(), Error: reason}
+
+	return fs.write(r, "not reserved", reservedBy(token))
+}
+
+// DeadLetters returns the dead letters.
+//
+// Returns the dead letters, the newest first.
+func (fs *FileStore) DeadLetters() ([]*Job, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	jobs := []*Job{}
+	for _, job := range fs.jobs {
+		if !job.Buried.IsZero() {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Buried.After(jobs[j].Buried)
+	})
+
+	return jobs, nil
+}
+
+// Revive returns a dead letter to the store as ready.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (fs *FileStore) Revive(id string) error {
+	return fs.write(record{Op: opRevive, ID: id}, "not buried", buried)
+}
+
+// Purge removes a dead letter.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (fs *FileStore) Purge(id string) error {
+	return fs.write(record{Op: opRemove, ID: id}, "not buried", buried)
+}
+
+// Compact rewrites the log with only the stored jobs.
+//
+// Returns an error if the log can't be rewritten.
+func (fs *FileStore) Compact() error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	return fs.compact()
+}
+
+// Close closes the log file.
+//
+// Returns an error if the log file can't be closed.
+func (fs *FileStore) Close() error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	return fs.file.Close()
+}
+
+// write appends and applies a record on a single job.
+//
+// - r: The record to write.
+// - missing: The error reason if the job is not on the expected state.
+// - expected: Checks the job's state before writing the record.
+//
+// Returns an error if the job is not on the expected state.
+func (fs *FileStore) write(r record, missing string, expected func(*Job) bool) error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	job, exists := fs.jobs[r.ID]
+	if !exists || !expected(job) {
+		return fmt.Errorf("job '%s' %s", r.ID, missing)
+	}
+
+	if err := fs.append(r); err != nil {
+		return err
+	}
+
+	fs.apply(r)
+
+	if fs.records > fs.CompactThreshold*(len(fs.jobs)+1) {
+		return fs.compact()
+	}
+
+	return nil
+}
+
+// apply applies a log record to the in memory jobs index.
+//
+// - r: The record to apply.
+//
+// Returns nothing.
+func (fs *FileStore) apply(r record) {
+	switch r.Op {
+	case opEnqueue:
+		fs.seq++
+		fs.jobs[r.Job.ID] = r.Job
+		fs.seqs[r.Job.ID] = fs.seq
+	case opReserve:
+		if job, exists := fs.jobs[r.ID]; exists {
+			job.Reserved = true
+			job.Attempts++
+			job.Lease = r.Lease
+			job.Token = r.Token
+		}
+	case opAck, opRemove:
+		delete(fs.jobs, r.ID)
+		delete(fs.seqs, r.ID)
+	case opNack:
+		if job, exists := fs.jobs[r.ID]; exists {
+			job.Reserved = false
+			job.Lease = time.Time{}
+			job.Token = ""
+			if !r.Schedule.IsZero() {
+				job.Schedule = r.Schedule
+			}
+		}
+	case opExtend:
+		if job, exists := fs.jobs[r.ID]; exists {
+			job.Lease = r.Lease
+		}
+	case opBury:
+		if job, exists := fs.jobs[r.ID]; exists {
+			job.Reserved = false
+			job.Lease = time.Time{}
+			job.Token = ""
+			job.Buried = r.Buried
+			job.Error = r.Error
+		}
+	case opRevive:
+		if job, exists := fs.jobs[r.ID]; exists {
+			job.Attempts = 0
+			job.Buried = time.Time{}
+			job.Error = ""
+		}
+	}
+}
+
+// append appends and syncs a record to the log.
+//
+// - r: The record to append.
+//
+// Returns an error if the record can't be written.
+func (fs *FileStore) append(r record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("record not encoded. Err: %v", err)
+	}
+
+	if _, err := fs.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("record not written. Err: %v", err)
+	}
+
+	if err := fs.file.Sync(); err != nil {
+		return fmt.Errorf("record not synced. Err: %v", err)
+	}
+
+	fs.records++
+
+	return nil
+}
+
+// replay reads the log, if any, rebuilding the in memory jobs index.
+//
+// Returns an error if the log can't be read.
+func (fs *FileStore) replay() error {
+	file, err := os.Open(fs.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("log '%s' not opened. Err: %v", fs.path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// A partially written last record is ignored.
+			continue
+		}
+
+		if r.Op == opEnqueue && r.Job == nil {
+			continue
+		}
+
+		fs.apply(r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("log '%s' not read. Err: %v", fs.path, err)
+	}
+
+	return nil
+}
+
+// compact rewrites the log with an enqueue record per stored job, keeping the
+// job's reservation state, the new log is written on a temporary file and
+// then renamed over the log.
+//
+// Returns an error if the log can't be rewritten.
+func (fs *FileStore) compact() error {
+	tmp := fs.path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("log '%s' not created. Err: %v", tmp, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, job := range fs.sorted() {
+		line, err := json.Marshal(record{Op: opEnqueue, Job: job})
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("record not encoded. Err: %v", err)
+		}
+
+		writer.Write(append(line, '\n'))
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("log '%s' not written. Err: %v", tmp, err)
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("log '%s' not synced. Err: %v", tmp, err)
+	}
+	file.Close()
+
+	if err := os.Rename(tmp, fs.path); err != nil {
+		return fmt.Errorf("log '%s' not renamed. Err: %v", tmp, err)
+	}
+
+	if fs.file != nil {
+		fs.file.Close()
+	}
+
+	fs.file, err = os.OpenFile(fs.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("log '%s' not opened. Err: %v", fs.path, err)
+	}
+
+	fs.records = len(fs.jobs)
+
+	return nil
+}
+
+// sorted returns the stored jobs sorted by their enqueue order.
+//
+// Returns the sorted jobs.
+func (fs *FileStore) sorted() []*Job {
+	jobs := make([]*Job, 0, len(fs.jobs))
+	for _, job := range fs.jobs {
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return fs.seqs[jobs[i].ID] < fs.seqs[jobs[j].ID]
+	})
+
+	return jobs
+}
+
+// reservedBy returns a check for a job reserved by the given token's
+// reservation.
+func reservedBy(token string) func(*Job) bool {
+	return func(job *Job) bool {
+		return job.Reserved && job.Token == token
+	}
+}
+
+// buried checks if a job is a dead letter.
+func buried(job *Job) bool {
+	return !job.Buried.IsZero()
+}
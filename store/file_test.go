@@ -0,0 +1,187 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when FileStore succeed enqueuing, reserving and acking jobs", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		assert.Nil(fs.Enqueue(&Job{ID: "1", Type: "foo", Payload: []byte(`{}`)}))
+		assert.Nil(fs.Enqueue(&Job{ID: "2", Type: "foo"}))
+
+		job, err := fs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal([]byte(`{}`), job.Payload)
+		assert.Equal(1, job.Attempts)
+
+		assert.Nil(fs.Nack("1", job.Token, 0))
+
+		job, err = fs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal(2, job.Attempts)
+
+		assert.Nil(fs.Ack("1", job.Token))
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Len(jobs, 1)
+		assert.Equal("2", jobs[0].ID)
+	})
+
+	t.Run("when FileStore succeed holding the scheduled jobs", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		assert.Nil(fs.Schedule(&Job{ID: "1"}, time.Now().Add(time.Hour)))
+		assert.Nil(fs.Schedule(&Job{ID: "2"}, time.Now().Add(-time.Second)))
+
+		job, err := fs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("2", job.ID)
+
+		_, err = fs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+	})
+
+	t.Run("when FileStore succeed returning the jobs with an expired lease", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		assert.Nil(fs.Enqueue(&Job{ID: "1"}))
+
+		_, err = fs.Reserve(10 * time.Millisecond)
+		assert.Nil(err)
+
+		_, err = fs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		job, err := fs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal(2, job.Attempts)
+	})
+
+	t.Run("when FileStore succeed extending a job's lease", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		assert.Nil(fs.Enqueue(&Job{ID: "1"}))
+
+		job, err := fs.Reserve(10 * time.Millisecond)
+		assert.Nil(err)
+		assert.Nil(fs.Extend("1", job.Token, time.Minute))
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = fs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+
+		err = fs.Extend("2", job.Token, time.Minute)
+		assert.Equal("job '2' not reserved", err.Error())
+	})
+
+	t.Run("when FileStore succeed restoring the jobs after reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jobs.log")
+
+		fs, err := NewFileStore(path)
+		assert.Nil(err)
+
+		assert.Nil(fs.Enqueue(&Job{ID: "1"}))
+		assert.Nil(fs.Enqueue(&Job{ID: "2"}))
+		assert.Nil(fs.Enqueue(&Job{ID: "3"}))
+
+		_, err = fs.Reserve(time.Minute)
+		assert.Nil(err)
+		job, err := fs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Nil(fs.Ack("2", job.Token))
+		assert.Nil(fs.Close())
+
+		fs, err = NewFileStore(path)
+		assert.Nil(err)
+		defer fs.Close()
+
+		jobs, err := fs.List()
+		assert.Nil(err)
+		assert.Len(jobs, 2)
+		assert.Equal("1", jobs[0].ID)
+		assert.False(jobs[0].Reserved)
+		assert.Equal(1, jobs[0].Attempts)
+		assert.Equal("3", jobs[1].ID)
+	})
+
+	t.Run("when FileStore succeed compacting the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jobs.log")
+
+		fs, err := NewFileStore(path)
+		assert.Nil(err)
+		defer fs.Close()
+
+		for i := 0; i < 20; i++ {
+			assert.Nil(fs.Enqueue(&Job{ID: "foo"}))
+			job, err := fs.Reserve(time.Minute)
+			assert.Nil(err)
+			assert.Nil(fs.Ack("foo", job.Token))
+		}
+		assert.Nil(fs.Enqueue(&Job{ID: "bar"}))
+		assert.Nil(fs.Compact())
+
+		content, err := os.ReadFile(path)
+		assert.Nil(err)
+		assert.Contains(string(content), `"id":"bar"`)
+		assert.NotContains(string(content), `"id":"foo"`)
+	})
+
+	t.Run("when FileStore succeed burying, reviving and purging dead letters", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		testAdmin(t, fs)
+	})
+
+	t.Run("when FileStore succeed holding a job nacked with a delay", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		testRetryDelay(t, fs)
+	})
+
+	t.Run("when FileStore fails settling a job from an expired reservation", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		testReservation(t, fs)
+	})
+
+	t.Run("when FileStore fails acking a not reserved job", func(t *testing.T) {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		assert.Nil(fs.Enqueue(&Job{ID: "1"}))
+
+		err = fs.Ack("1", "")
+		assert.Equal("job '1' not reserved", err.Error())
+	})
+}
@@ -0,0 +1,511 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The RedisStore scripts, every Store operation is run as a single script so
+// it's atomic even when many processes share the same keys.
+var (
+	// redisEnqueue stores the job's hash and pushes it to the ready list, or
+	// to the scheduled sorted set if it's scheduled in the future.
+	redisEnqueue = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'type', ARGV[2], 'payload', ARGV[3],
+	'enqueued', ARGV[4], 'schedule', ARGV[5], 'attempts', 0)
+redis.call('ZADD', KEYS[2], ARGV[6], ARGV[1])
+if tonumber(ARGV[7]) > tonumber(ARGV[8]) then
+	redis.call('ZADD', KEYS[4], ARGV[7], ARGV[1])
+else
+	redis.call('RPUSH', KEYS[3], ARGV[1])
+end
+return 1
+`)
+
+	// redisReserve moves the due scheduled jobs and the expired lease jobs to
+	// the ready list, then moves the oldest ready job to the processing list
+	// leasing it. The reserved job's hash key, ARGV[3] .. id, is not known
+	// before running the script so it's not declared on KEYS.
+	redisReserve = redis.NewScript(`
+local now = tonumber(ARGV[1])
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now)) do
+	redis.call('ZREM', KEYS[3], id)
+	redis.call('RPUSH', KEYS[1], id)
+end
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[4], '-inf', now)) do
+	redis.call('ZREM', KEYS[4], id)
+	redis.call('LREM', KEYS[2], 1, id)
+	redis.call('LPUSH', KEYS[1], id)
+end
+local id = redis.call('LMOVE', KEYS[1], KEYS[2], 'LEFT', 'RIGHT')
+if not id then
+	return false
+end
+redis.call('ZADD', KEYS[4], ARGV[2], id)
+redis.call('HINCRBY', ARGV[3] .. id, 'attempts', 1)
+redis.call('HSET', ARGV[3] .. id, 'token', ARGV[4])
+return {id, redis.call('HGETALL', ARGV[3] .. id)}
+`)
+
+	// redisExtend extends a reserved job's lease, if it's still reserved by
+	// the given token's reservation.
+	redisExtend = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) or
+	redis.call('HGET', KEYS[2], 'token') ~= ARGV[3] then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+	// redisAck removes a reserved job, if it's still reserved by the given
+	// token's reservation.
+	redisAck = redis.NewScript(`
+if redis.call('HGET', KEYS[4], 'token') ~= ARGV[2] or
+	redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('LREM', KEYS[2], 1, ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('DEL', KEYS[4])
+return 1
+`)
+
+	// redisNack returns a reserved job to the front of the ready list, or to
+	// the scheduled sorted set by it's retry time if it's delayed, if it's
+	// still reserved by the given token's reservation.
+	redisNack = redis.NewScript(`
+if redis.call('HGET', KEYS[4], 'token') ~= ARGV[2] or
+	redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('LREM', KEYS[2], 1, ARGV[1])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('ZADD', KEYS[5], ARGV[3], ARGV[1])
+	redis.call('HSET', KEYS[4], 'schedule', ARGV[4])
+else
+	redis.call('LPUSH', KEYS[3], ARGV[1])
+end
+redis.call('HDEL', KEYS[4], 'token')
+return 1
+`)
+
+	// redisRemove removes a ready or scheduled job.
+	redisRemove = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+if redis.call('LREM', KEYS[2], 0, ARGV[1]) + redis.call('ZREM', KEYS[3], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('ZREM', KEYS[4], ARGV[1])
+redis.call('DEL', KEYS[5])
+return 1
+`)
+
+	// redisBury moves a reserved job to the dead letters sorted set, by it's
+	// burial time, keeping it's hash with the job's last error, if it's still
+	// reserved by the given token's reservation.
+	redisBury = redis.NewScript(`
+if redis.call('HGET', KEYS[5], 'token') ~= ARGV[5] or
+	redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('LREM', KEYS[2], 1, ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('ZADD', KEYS[4], ARGV[2], ARGV[1])
+redis.call('HSET', KEYS[5], 'error', ARGV[3], 'buried', ARGV[4])
+redis.call('HDEL', KEYS[5], 'token')
+return 1
+`)
+
+	// redisRevive moves a dead letter to the back of the ready list.
+	redisRevive = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[4], 'attempts', 0)
+redis.call('HDEL', KEYS[4], 'error', 'buried')
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+redis.call('RPUSH', KEYS[3], ARGV[1])
+return 1
+`)
+
+	// redisPurge removes a dead letter.
+	redisPurge = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('DEL', KEYS[2])
+return 1
+`)
+)
+
+// RedisStore is a Store backed by Redis, so many processes running thrall's
+// workers can consume the same queue. The ready jobs are kept on a list, the
+// scheduled ones on a sorted set by their execution time, and the reserved
+// ones are moved to a processing list, following the reliable queue pattern,
+// with their lease on a sorted set, so the jobs of a dead process return to
+// the ready list once their lease expires. The dead letters are kept on a
+// sorted set by their burial time.
+//
+// The RedisStore is not Redis Cluster safe, as the Reserve script accesses the
+// reserved job's hash without declaring it's key, which Redis Cluster doesn't
+// guarantee to support. Use it on a single Redis or a Sentinel deployment.
+type RedisStore struct {
+	// Prefix is prefixed to every key, It's wrapped on a hash tag so all the
+	// keys share the same hash slot.
+	Prefix string
+
+	client redis.UniversalClient
+}
+
+// NewRedisStore creates a RedisStore on the given Redis client.
+//
+// - client: The Redis client, as a redis.NewClient(&redis.Options{...}).
+// - opts: function option initializers, check the following With.. funcs.
+//
+// Returns the RedisStore.
+func NewRedisStore(client redis.UniversalClient, opts ...func(*RedisStore)) *RedisStore {
+	rs := &RedisStore{
+		Prefix: "thrall",
+		client: client,
+	}
+
+	for _, option := range opts {
+		option(rs)
+	}
+
+	return rs
+}
+
+// WithKeyPrefix is an optional func for NewRedisStore, It does configure the
+// keys prefix, so many queues can share the same Redis. It defaults to
+// "thrall".
+//
+// - prefix: The keys prefix.
+//
+// Returns a optional configuration function.
+func WithKeyPrefix(prefix string) func(*RedisStore) {
+	return func(rs *RedisStore) {
+		rs.Prefix = prefix
+	}
+}
+
+// Enqueue stores a job ready to be reserved.
+//
+// - job: The job to store.
+//
+// Returns an error if the job can't be stored.
+func (rs *RedisStore) Enqueue(job *Job) error {
+	if job.Enqueued.IsZero() {
+		job.Enqueued = time.Now()
+	}
+
+	stored, err := redisEnqueue.Run(context.Background(), rs.client,
+		[]string{rs.jobKey(job.ID), rs.key("ids"), rs.key("ready"), rs.key("scheduled")},
+		job.ID, job.Type, job.Payload,
+		toNanos(job.Enqueued), toNanos(job.Schedule),
+		toMillis(job.Enqueued), toMillis(job.Schedule), toMillis(time.Now()),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("job '%s' not stored. Err: %v", job.ID, err)
+	}
+
+	if stored == 0 {
+		return fmt.Errorf("job '%s' already stored", job.ID)
+	}
+
+	return nil
+}
+
+// Schedule stores a job that would be ready to be reserved at when.
+//
+// - job: The job to store.
+// - when: The job programmed execution time.
+//
+// Returns an error if the job can't be stored.
+func (rs *RedisStore) Schedule(job *Job, when time.Time) error {
+	job.Schedule = when
+	return rs.Enqueue(job)
+}
+
+// Reserve reserves the oldest ready job, the reserved jobs with an expired
+// lease are ready too.
+//
+// - visibility: The reservation's visibility timeout.
+//
+// Returns the reserved job, ErrNoJobs if none is ready.
+func (rs *RedisStore) Reserve(visibility time.Duration) (*Job, error) {
+	now := time.Now()
+	lease := now.Add(visibility)
+
+	result, err := redisReserve.Run(context.Background(), rs.client,
+		[]string{rs.key("ready"), rs.key("processing"), rs.key("scheduled"), rs.key("leases")},
+		toMillis(now), toMillis(lease), rs.jobKey(""), newToken(),
+	).Slice()
+	if err == redis.Nil {
+		return nil, ErrNoJobs
+	}
+	if err != nil {
+		return nil, fmt.Errorf("job not reserved. Err: %v", err)
+	}
+
+	fields := map[string]string{}
+	values, _ := result[1].([]interface{})
+	for i := 0; i+1 < len(values); i += 2 {
+		fields[fmt.Sprint(values[i])] = fmt.Sprint(values[i+1])
+	}
+
+	job := parseRedisJob(fmt.Sprint(result[0]), fields)
+	job.Reserved = true
+	job.Lease = fromMillis(toMillis(lease))
+
+	return job, nil
+}
+
+// Extend extends a reserved job's lease.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - visibility: The visibility timeout to extend the lease by, from now.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (rs *RedisStore) Extend(id, token string, visibility time.Duration) error {
+	return rs.run(id, "not reserved", redisExtend,
+		[]string{rs.key("leases"), rs.jobKey(id)},
+		id, toMillis(time.Now().Add(visibility)), token)
+}
+
+// Ack removes a reserved job from the store.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (rs *RedisStore) Ack(id, token string) error {
+	return rs.run(id, "not reserved", redisAck,
+		[]string{rs.key("leases"), rs.key("processing"), rs.key("ids"), rs.jobKey(id)},
+		id, token)
+}
+
+// Nack returns a reserved job to the store, ready once the delay has passed.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - delay: The time to wait before the job is ready, zero for right away.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (rs *RedisStore) Nack(id, token string, delay time.Duration) error {
+	var retry time.Time
+	if delay > 0 {
+		retry = time.Now().Add(delay)
+	}
+
+	return rs.run(id, "not reserved", redisNack,
+		[]string{rs.key("leases"), rs.key("processing"), rs.key("ready"), rs.jobKey(id),
+			rs.key("scheduled")},
+		id, token, toMillis(retry), toNanos(retry))
+}
+
+// List returns all the stored jobs sorted by their enqueue order, but the
+// dead letters.
+//
+// Returns the stored jobs.
+func (rs *RedisStore) List() ([]*Job, error) {
+	ctx := context.Background()
+
+	ids, err := rs.client.ZRange(ctx, rs.key("ids"), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("jobs not listed. Err: %v", err)
+	}
+
+	pipe := rs.client.Pipeline()
+	hashes := make([]*redis.MapStringStringCmd, len(ids))
+	leases := make([]*redis.FloatCmd, len(ids))
+	for i, id := range ids {
+		hashes[i] = pipe.HGetAll(ctx, rs.jobKey(id))
+		leases[i] = pipe.ZScore(ctx, rs.key("leases"), id)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("jobs not listed. Err: %v", err)
+	}
+
+	jobs := []*Job{}
+	for i, id := range ids {
+		job := parseRedisJob(id, hashes[i].Val())
+
+		if lease, err := leases[i].Result(); err == nil {
+			job.Reserved = true
+			job.Lease = fromMillis(int64(lease))
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Remove removes a job that is not reserved.
+//
+// - id: The job ID.
+//
+// Returns an error if the job is not queued or scheduled.
+func (rs *RedisStore) Remove(id string) error {
+	return rs.run(id, "not queued", redisRemove,
+		[]string{rs.key("leases"), rs.key("ready"), rs.key("scheduled"), rs.key("ids"),
+			rs.jobKey(id)}, id)
+}
+
+// Bury moves a reserved job to the dead letters.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - reason: The job's last error.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (rs *RedisStore) Bury(id, token, reason string) error {
+	now := time.Now()
+
+	return rs.run(id, "not reserved", redisBury,
+		[]string{rs.key("leases"), rs.key("processing"), rs.key("ids"), rs.key("dead"),
+			rs.jobKey(id)}, id, toMillis(now), reason, toNanos(now), token)
+}
+
+// DeadLetters returns the dead letters.
+//
+// Returns the dead letters, the newest first.
+func (rs *RedisStore) DeadLetters() ([]*Job, error) {
+	ctx := context.Background()
+
+	ids, err := rs.client.ZRevRange(ctx, rs.key("dead"), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("dead letters not listed. Err: %v", err)
+	}
+
+	pipe := rs.client.Pipeline()
+	hashes := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		hashes[i] = pipe.HGetAll(ctx, rs.jobKey(id))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("dead letters not listed. Err: %v", err)
+	}
+
+	jobs := []*Job{}
+	for i, id := range ids {
+		jobs = append(jobs, parseRedisJob(id, hashes[i].Val()))
+	}
+
+	return jobs, nil
+}
+
+// Revive returns a dead letter to the store as ready.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (rs *RedisStore) Revive(id string) error {
+	return rs.run(id, "not buried", redisRevive,
+		[]string{rs.key("dead"), rs.key("ids"), rs.key("ready"), rs.jobKey(id)},
+		id, toMillis(time.Now()))
+}
+
+// Purge removes a dead letter.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (rs *RedisStore) Purge(id string) error {
+	return rs.run(id, "not buried", redisPurge, []string{rs.key("dead"), rs.jobKey(id)}, id)
+}
+
+// run runs a script on a single job.
+//
+// - id: The job ID.
+// - missing: The error reason if the script returns 0.
+// - script: The script to run, it returns 0 on a job's unexpected state.
+// - keys: The script keys.
+// - args: The script arguments.
+//
+// Returns an error if the job is not on the script's expected state.
+func (rs *RedisStore) run(id, missing string, script *redis.Script, keys []string, args ...interface{}) error {
+	done, err := script.Run(context.Background(), rs.client, keys, args...).Int()
+	if err != nil {
+		return fmt.Errorf("job '%s' not updated. Err: %v", id, err)
+	}
+
+	if done == 0 {
+		return fmt.Errorf("job '%s' %s", id, missing)
+	}
+
+	return nil
+}
+
+// key returns the prefixed key for name.
+func (rs *RedisStore) key(name string) string {
+	return "{" + rs.Prefix + "}:" + name
+}
+
+// jobKey returns the job's hash key.
+func (rs *RedisStore) jobKey(id string) string {
+	return rs.key("job:") + id
+}
+
+// parseRedisJob builds a job from it's hash fields.
+//
+// - id: The job ID.
+// - fields: The job's hash fields.
+//
+// Returns the job.
+func parseRedisJob(id string, fields map[string]string) *Job {
+	enqueued, _ := strconv.ParseInt(fields["enqueued"], 10, 64)
+	schedule, _ := strconv.ParseInt(fields["schedule"], 10, 64)
+	attempts, _ := strconv.Atoi(fields["attempts"])
+	buried, _ := strconv.ParseInt(fields["buried"], 10, 64)
+
+	job := &Job{
+		ID:       id,
+		Type:     fields["type"],
+		Enqueued: fromNanos(enqueued),
+		Schedule: fromNanos(schedule),
+		Attempts: attempts,
+		Buried:   fromNanos(buried),
+		Error:    fields["error"],
+		Token:    fields["token"],
+	}
+
+	if payload := fields["payload"]; payload != "" {
+		job.Payload = []byte(payload)
+	}
+
+	return job
+}
+
+// toMillis converts a time to unix milliseconds, the sorted sets scores, the
+// zero time is 0.
+func toMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixMilli()
+}
+
+// fromMillis converts unix milliseconds to a time, 0 is the zero time.
+func fromMillis(millis int64) time.Time {
+	if millis == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(millis)
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisStore(client), server
+}
+
+func TestRedisStore(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when RedisStore succeed enqueuing, reserving and acking jobs", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+
+		assert.Nil(rs.Enqueue(&Job{ID: "1", Type: "foo", Payload: []byte(`{}`)}))
+		assert.Nil(rs.Enqueue(&Job{ID: "2", Type: "foo"}))
+
+		job, err := rs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal("foo", job.Type)
+		assert.Equal([]byte(`{}`), job.Payload)
+		assert.Equal(1, job.Attempts)
+
+		assert.Nil(rs.Nack("1", job.Token, 0))
+
+		job, err = rs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal(2, job.Attempts)
+
+		jobs, err := rs.List()
+		assert.Nil(err)
+		assert.Len(jobs, 2)
+		assert.True(jobs[0].Reserved)
+
+		assert.Nil(rs.Ack("1", job.Token))
+
+		jobs, err = rs.List()
+		assert.Nil(err)
+		assert.Len(jobs, 1)
+		assert.Equal("2", jobs[0].ID)
+		assert.False(jobs[0].Reserved)
+	})
+
+	t.Run("when RedisStore succeed holding the scheduled jobs", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+
+		assert.Nil(rs.Schedule(&Job{ID: "1"}, time.Now().Add(time.Hour)))
+		assert.Nil(rs.Schedule(&Job{ID: "2"}, time.Now().Add(50*time.Millisecond)))
+
+		_, err := rs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+
+		time.Sleep(60 * time.Millisecond)
+
+		job, err := rs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("2", job.ID)
+
+		_, err = rs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+	})
+
+	t.Run("when RedisStore succeed returning the jobs with an expired lease", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+
+		assert.Nil(rs.Enqueue(&Job{ID: "1"}))
+
+		_, err := rs.Reserve(10 * time.Millisecond)
+		assert.Nil(err)
+
+		_, err = rs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		job, err := rs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal(2, job.Attempts)
+	})
+
+	t.Run("when RedisStore succeed extending a job's lease", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+
+		assert.Nil(rs.Enqueue(&Job{ID: "1"}))
+
+		job, err := rs.Reserve(10 * time.Millisecond)
+		assert.Nil(err)
+		assert.Nil(rs.Extend("1", job.Token, time.Minute))
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = rs.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+	})
+
+	t.Run("when many RedisStores succeed sharing the same queue", func(t *testing.T) {
+		rs, server := newTestRedisStore(t)
+
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer client.Close()
+		other := NewRedisStore(client)
+
+		assert.Nil(rs.Enqueue(&Job{ID: "1"}))
+		assert.Nil(rs.Enqueue(&Job{ID: "2"}))
+
+		first, err := other.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", first.ID)
+
+		job, err := rs.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("2", job.ID)
+
+		assert.Nil(rs.Ack("1", first.Token))
+	})
+
+	t.Run("when RedisStore succeed burying, reviving and purging dead letters", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+		testAdmin(t, rs)
+	})
+
+	t.Run("when RedisStore succeed holding a job nacked with a delay", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+		testRetryDelay(t, rs)
+	})
+
+	t.Run("when RedisStore fails settling a job from an expired reservation", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+		testReservation(t, rs)
+	})
+
+	t.Run("when RedisStore fails acking a not reserved job", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+
+		assert.Nil(rs.Enqueue(&Job{ID: "1"}))
+
+		err := rs.Ack("1", "")
+		assert.Equal("job '1' not reserved", err.Error())
+	})
+
+	t.Run("when RedisStore fails enqueuing a job twice", func(t *testing.T) {
+		rs, _ := newTestRedisStore(t)
+
+		assert.Nil(rs.Enqueue(&Job{ID: "1"}))
+
+		err := rs.Enqueue(&Job{ID: "1"})
+		assert.Equal("job '1' already stored", err.Error())
+	})
+}
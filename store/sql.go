@@ -0,0 +1,553 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect defines the SQL differences between the databases supported by the
+// SQLStore.
+type Dialect struct {
+	Name string
+
+	// Blob is the column type used to store the jobs payload.
+	Blob string
+
+	// Numbered is set for the databases that use numbered placeholders, as
+	// "$1", instead of "?".
+	Numbered bool
+
+	// SkipLocked is set for the databases that support "FOR UPDATE SKIP
+	// LOCKED", so concurrent reservations don't block each other.
+	SkipLocked bool
+}
+
+// The supported SQL dialects.
+var (
+	Postgres = Dialect{Name: "postgres", Blob: "BYTEA", Numbered: true, SkipLocked: true}
+	MySQL    = Dialect{Name: "mysql", Blob: "LONGBLOB", SkipLocked: true}
+	SQLite   = Dialect{Name: "sqlite", Blob: "BLOB"}
+)
+
+// rebind rewrites the "?" placeholders of a query for the dialect.
+//
+// - query: The query to rewrite.
+//
+// Returns the rewritten query.
+func (d Dialect) rebind(query string) string {
+	if !d.Numbered {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// migrations are the SQLStore schema migrations, every migration is a list
+// of statements applied on a single transaction. The "{table}" and "{blob}"
+// tokens are replaced by the jobs table name and the dialect's Blob type.
+var migrations = [][]string{
+	{
+		`CREATE TABLE {table} (
+			id VARCHAR(64) NOT NULL PRIMARY KEY,
+			type VARCHAR(255) NOT NULL,
+			payload {blob},
+			enqueued_at BIGINT NOT NULL,
+			schedule_at BIGINT NOT NULL,
+			reserved INTEGER NOT NULL,
+			attempts INTEGER NOT NULL,
+			lease_until BIGINT NOT NULL
+		)`,
+		`CREATE INDEX {table}_ready ON {table} (reserved, schedule_at, enqueued_at)`,
+	},
+	{
+		`ALTER TABLE {table} ADD COLUMN buried_at BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE {table} ADD COLUMN last_error TEXT`,
+	},
+	{
+		`ALTER TABLE {table} ADD COLUMN lease_token VARCHAR(64) NOT NULL DEFAULT ''`,
+	},
+}
+
+// SQLStore is a Store backed by a database/sql database, as PostgreSQL, MySQL
+// or SQLite. The jobs are reserved with "SELECT ... FOR UPDATE SKIP LOCKED"
+// on the databases that support it, so many processes can share the same
+// jobs table. The schema must be created with Migrate before using it.
+type SQLStore struct {
+	// Table is the jobs table name, and MigrationsTable the applied schema
+	// migrations table name.
+	Table           string
+	MigrationsTable string
+
+	// Dialect is the database SQL dialect.
+	Dialect Dialect
+
+	db *sql.DB
+}
+
+// NewSQLStore creates a SQLStore on the given database.
+//
+// - db: The database, as a sql.Open("postgres", dsn).
+// - opts: function option initializers, check the following With.. funcs.
+//
+// Returns the SQLStore.
+func NewSQLStore(db *sql.DB, opts ...func(*SQLStore)) *SQLStore {
+	s := &SQLStore{
+		Table:           "thrall_jobs",
+		MigrationsTable: "thrall_schema_migrations",
+		Dialect:         Postgres,
+		db:              db,
+	}
+
+	for _, option := range opts {
+		option(s)
+	}
+
+	return s
+}
+
+// WithDialect is an optional func for NewSQLStore, It does configure the
+// database SQL dialect, it defaults to Postgres.
+//
+// - dialect: The SQL dialect, as store.MySQL.
+//
+// Returns a optional configuration function.
+func WithDialect(dialect Dialect) func(*SQLStore) {
+	return func(s *SQLStore) {
+		s.Dialect = dialect
+	}
+}
+
+// WithTable is an optional func for NewSQLStore, It does configure the jobs
+// table name, the migrations table is named after it.
+//
+// - table: The jobs table name.
+//
+// Returns a optional configuration function.
+func WithTable(table string) func(*SQLStore) {
+	return func(s *SQLStore) {
+		s.Table = table
+		s.MigrationsTable = table + "_schema_migrations"
+	}
+}
+
+// Migrations returns the SQLStore schema migrations statements, in order, for
+// those that prefer to apply them with their own migration tool.
+//
+// Returns the migrations statements.
+func (s *SQLStore) Migrations() [][]string {
+	replacer := strings.NewReplacer("{table}", s.Table, "{blob}", s.Dialect.Blob)
+
+	statements := make([][]string, len(migrations))
+	for i, migration := range migrations {
+		for _, statement := range migration {
+			statements[i] = append(statements[i], replacer.Replace(statement))
+		}
+	}
+
+	return statements
+}
+
+// Migrate applies the pending schema migrations, the applied ones are tracked
+// on the MigrationsTable.
+//
+// Returns an error if any migration can't be applied.
+func (s *SQLStore) Migrate() error {
+	_, err := s.db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version INTEGER NOT NULL PRIMARY KEY)",
+		s.MigrationsTable))
+	if err != nil {
+		return fmt.Errorf("migrations table '%s' not created. Err: %v",
+			s.MigrationsTable, err)
+	}
+
+	var current int
+	err = s.db.QueryRow(fmt.Sprintf(
+		"SELECT COALESCE(MAX(version), 0) FROM %s", s.MigrationsTable)).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("migrations version not read. Err: %v", err)
+	}
+
+	for i, migration := range s.Migrations() {
+		version := i + 1
+		if version <= current {
+			continue
+		}
+
+		if err := s.migrate(version, migration); err != nil {
+			return fmt.Errorf("migration '%d' not applied. Err: %v", version, err)
+		}
+	}
+
+	return nil
+}
+
+// migrate applies a single schema migration.
+//
+// - version: The migration version.
+// - statements: The migration statements.
+//
+// Returns an error if the migration can't be applied.
+func (s *SQLStore) migrate(version int, statements []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(s.Dialect.rebind(fmt.Sprintf(
+		"INSERT INTO %s (version) VALUES (?)", s.MigrationsTable)), version)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Enqueue stores a job ready to be reserved.
+//
+// - job: The job to store.
+//
+// Returns an error if the job can't be stored.
+func (s *SQLStore) Enqueue(job *Job) error {
+	if job.Enqueued.IsZero() {
+		job.Enqueued = time.Now()
+	}
+
+	_, err := s.db.Exec(s.query(`INSERT INTO {table}
+		(id, type, payload, enqueued_at, schedule_at, reserved, attempts, lease_until)
+		VALUES (?, ?, ?, ?, ?, 0, 0, 0)`),
+		job.ID, job.Type, job.Payload, toNanos(job.Enqueued), toNanos(job.Schedule))
+	if err != nil {
+		return fmt.Errorf("job '%s' not stored. Err: %v", job.ID, err)
+	}
+
+	return nil
+}
+
+// Schedule stores a job that would be ready to be reserved at when.
+//
+// - job: The job to store.
+// - when: The job programmed execution time.
+//
+// Returns an error if the job can't be stored.
+func (s *SQLStore) Schedule(job *Job, when time.Time) error {
+	job.Schedule = when
+	return s.Enqueue(job)
+}
+
+// Reserve reserves the oldest ready job, the reserved jobs with an expired
+// lease are ready too.
+//
+// - visibility: The reservation's visibility timeout.
+//
+// Returns the reserved job, ErrNoJobs if none is ready.
+func (s *SQLStore) Reserve(visibility time.Duration) (*Job, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("job not reserved. Err: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	query := `SELECT id, type, payload, enqueued_at, schedule_at, attempts
+		FROM {table}
+		WHERE schedule_at <= ? AND (reserved = 0 OR lease_until <= ?) AND buried_at = 0
+		ORDER BY enqueued_at, id
+		LIMIT 1`
+	if s.Dialect.SkipLocked {
+		query += " FOR UPDATE SKIP LOCKED"
+	}
+
+	job, err := scanJob(tx.QueryRow(s.query(query), toNanos(now), toNanos(now)))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoJobs
+	}
+	if err != nil {
+		return nil, fmt.Errorf("job not reserved. Err: %v", err)
+	}
+
+	job.Reserved = true
+	job.Attempts++
+	job.Lease = now.Add(visibility)
+	job.Token = newToken()
+
+	_, err = tx.Exec(s.query(`UPDATE {table}
+		SET reserved = 1, attempts = ?, lease_until = ?, lease_token = ?
+		WHERE id = ?`),
+		job.Attempts, toNanos(job.Lease), job.Token, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("job '%s' not reserved. Err: %v", job.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("job '%s' not reserved. Err: %v", job.ID, err)
+	}
+
+	return job, nil
+}
+
+// Extend extends a reserved job's lease.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - visibility: The visibility timeout to extend the lease by, from now.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (s *SQLStore) Extend(id, token string, visibility time.Duration) error {
+	return s.update(id, "not reserved", `UPDATE {table} SET lease_until = ?
+		WHERE id = ? AND reserved = 1 AND lease_token = ?`,
+		toNanos(time.Now().Add(visibility)), id, token)
+}
+
+// Ack removes a reserved job from the store.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (s *SQLStore) Ack(id, token string) error {
+	return s.update(id, "not reserved", `DELETE FROM {table}
+		WHERE id = ? AND reserved = 1 AND lease_token = ?`, id, token)
+}
+
+// Nack returns a reserved job to the store, ready once the delay has passed.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - delay: The time to wait before the job is ready, zero for right away.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (s *SQLStore) Nack(id, token string, delay time.Duration) error {
+	if delay > 0 {
+		return s.update(id, "not reserved", `UPDATE {table}
+			SET reserved = 0, lease_until = 0, lease_token = '', schedule_at = ?
+			WHERE id = ? AND reserved = 1 AND lease_token = ?`,
+			toNanos(time.Now().Add(delay)), id, token)
+	}
+
+	return s.update(id, "not reserved", `UPDATE {table}
+		SET reserved = 0, lease_until = 0, lease_token = ''
+		WHERE id = ? AND reserved = 1 AND lease_token = ?`, id, token)
+}
+
+// List returns all the stored jobs sorted by their enqueue order, but the
+// dead letters.
+//
+// Returns the stored jobs.
+func (s *SQLStore) List() ([]*Job, error) {
+	rows, err := s.db.Query(s.query(`SELECT
+		id, type, payload, enqueued_at, schedule_at, attempts, reserved, lease_until
+		FROM {table}
+		WHERE buried_at = 0
+		ORDER BY enqueued_at, id`))
+	if err != nil {
+		return nil, fmt.Errorf("jobs not listed. Err: %v", err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		var reserved int
+		var lease int64
+
+		job, err := scanJob(rows, &reserved, &lease)
+		if err != nil {
+			return nil, fmt.Errorf("jobs not listed. Err: %v", err)
+		}
+
+		job.Reserved = reserved == 1
+		job.Lease = fromNanos(lease)
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("jobs not listed. Err: %v", err)
+	}
+
+	return jobs, nil
+}
+
+// Remove removes a job that is not reserved.
+//
+// - id: The job ID.
+//
+// Returns an error if the job is not queued or scheduled.
+func (s *SQLStore) Remove(id string) error {
+	return s.update(id, "not queued", `DELETE FROM {table}
+		WHERE id = ? AND reserved = 0 AND buried_at = 0`, id)
+}
+
+// Bury moves a reserved job to the dead letters.
+//
+// - id: The job ID.
+// - token: The job's reservation token.
+// - reason: The job's last error.
+//
+// Returns an error if the job is not reserved by the token's reservation.
+func (s *SQLStore) Bury(id, token, reason string) error {
+	return s.update(id, "not reserved", `UPDATE {table}
+		SET reserved = 0, lease_until = 0, lease_token = '', buried_at = ?, last_error = ?
+		WHERE id = ? AND reserved = 1 AND lease_token = ?`,
+		toNanos(time.Now()), reason, id, token)
+}
+
+// DeadLetters returns the dead letters.
+//
+// Returns the dead letters, the newest first.
+func (s *SQLStore) DeadLetters() ([]*Job, error) {
+	rows, err := s.db.Query(s.query(`SELECT
+		id, type, payload, enqueued_at, schedule_at, attempts, buried_at, last_error
+		FROM {table}
+		WHERE buried_at > 0
+		ORDER BY buried_at DESC, id`))
+	if err != nil {
+		return nil, fmt.Errorf("dead letters not listed. Err: %v", err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		var buried int64
+		var reason sql.NullString
+
+		job, err := scanJob(rows, &buried, &reason)
+		if err != nil {
+			return nil, fmt.Errorf("dead letters not listed. Err: %v", err)
+		}
+
+		job.Buried = fromNanos(buried)
+		job.Error = reason.String
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("dead letters not listed. Err: %v", err)
+	}
+
+	return jobs, nil
+}
+
+// Revive returns a dead letter to the store as ready.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (s *SQLStore) Revive(id string) error {
+	return s.update(id, "not buried", `UPDATE {table}
+		SET attempts = 0, buried_at = 0, last_error = NULL
+		WHERE id = ? AND buried_at > 0`, id)
+}
+
+// Purge removes a dead letter.
+//
+// - id: The dead letter's job ID.
+//
+// Returns an error if the job is not a dead letter.
+func (s *SQLStore) Purge(id string) error {
+	return s.update(id, "not buried", `DELETE FROM {table} WHERE id = ? AND buried_at > 0`, id)
+}
+
+// update runs a statement on a single job.
+//
+// - id: The job ID.
+// - missing: The error reason if the statement doesn't affect the job.
+// - query: The statement to run.
+// - args: The statement arguments.
+//
+// Returns an error if the job is not on the statement's expected state.
+func (s *SQLStore) update(id, missing, query string, args ...interface{}) error {
+	result, err := s.db.Exec(s.query(query), args...)
+	if err != nil {
+		return fmt.Errorf("job '%s' not updated. Err: %v", id, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("job '%s' not updated. Err: %v", id, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("job '%s' %s", id, missing)
+	}
+
+	return nil
+}
+
+// query prepares a query for the SQLStore's table and dialect.
+//
+// - query: The query, with the "{table}" token and "?" placeholders.
+//
+// Returns the prepared query.
+func (s *SQLStore) query(query string) string {
+	return s.Dialect.rebind(strings.ReplaceAll(query, "{table}", s.Table))
+}
+
+// scanner is implemented by both sql.Row and sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob scans a job row, the row columns are id, type, payload,
+// enqueued_at, schedule_at and attempts followed by any extra column.
+//
+// - row: The row to scan.
+// - extra: The extra columns destinations.
+//
+// Returns the scanned job.
+func scanJob(row scanner, extra ...interface{}) (*Job, error) {
+	job := &Job{}
+	var enqueued, schedule int64
+
+	dest := append([]interface{}{
+		&job.ID, &job.Type, &job.Payload, &enqueued, &schedule, &job.Attempts,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	job.Enqueued = fromNanos(enqueued)
+	job.Schedule = fromNanos(schedule)
+
+	return job, nil
+}
+
+// toNanos converts a time to unix nanoseconds, the zero time is 0.
+func toNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+// fromNanos converts unix nanoseconds to a time, 0 is the zero time.
+func fromNanos(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
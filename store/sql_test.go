@@ -0,0 +1,150 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func newTestSQLStore(t *testing.T) *SQLStore {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := NewSQLStore(db, WithDialect(SQLite))
+	if err := s.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestSQLStore(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when SQLStore succeed enqueuing, reserving and acking jobs", func(t *testing.T) {
+		s := newTestSQLStore(t)
+
+		assert.Nil(s.Enqueue(&Job{ID: "1", Type: "foo", Payload: []byte(`{}`)}))
+		assert.Nil(s.Enqueue(&Job{ID: "2", Type: "foo"}))
+
+		job, err := s.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal("foo", job.Type)
+		assert.Equal([]byte(`{}`), job.Payload)
+		assert.Equal(1, job.Attempts)
+
+		assert.Nil(s.Nack("1", job.Token, 0))
+
+		job, err = s.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal(2, job.Attempts)
+
+		assert.Nil(s.Ack("1", job.Token))
+
+		jobs, err := s.List()
+		assert.Nil(err)
+		assert.Len(jobs, 1)
+		assert.Equal("2", jobs[0].ID)
+		assert.False(jobs[0].Reserved)
+	})
+
+	t.Run("when SQLStore succeed holding the scheduled jobs", func(t *testing.T) {
+		s := newTestSQLStore(t)
+
+		assert.Nil(s.Schedule(&Job{ID: "1"}, time.Now().Add(time.Hour)))
+		assert.Nil(s.Schedule(&Job{ID: "2"}, time.Now().Add(-time.Second)))
+
+		job, err := s.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("2", job.ID)
+
+		_, err = s.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+	})
+
+	t.Run("when SQLStore succeed returning the jobs with an expired lease", func(t *testing.T) {
+		s := newTestSQLStore(t)
+
+		assert.Nil(s.Enqueue(&Job{ID: "1"}))
+
+		_, err := s.Reserve(10 * time.Millisecond)
+		assert.Nil(err)
+
+		_, err = s.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		job, err := s.Reserve(time.Minute)
+		assert.Nil(err)
+		assert.Equal("1", job.ID)
+		assert.Equal(2, job.Attempts)
+	})
+
+	t.Run("when SQLStore succeed extending a job's lease", func(t *testing.T) {
+		s := newTestSQLStore(t)
+
+		assert.Nil(s.Enqueue(&Job{ID: "1"}))
+
+		job, err := s.Reserve(10 * time.Millisecond)
+		assert.Nil(err)
+		assert.Nil(s.Extend("1", job.Token, time.Minute))
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = s.Reserve(time.Minute)
+		assert.Equal(ErrNoJobs, err)
+	})
+
+	t.Run("when SQLStore succeed migrating twice", func(t *testing.T) {
+		s := newTestSQLStore(t)
+		assert.Nil(s.Migrate())
+	})
+
+	t.Run("when SQLStore succeed burying, reviving and purging dead letters", func(t *testing.T) {
+		testAdmin(t, newTestSQLStore(t))
+	})
+
+	t.Run("when SQLStore succeed holding a job nacked with a delay", func(t *testing.T) {
+		testRetryDelay(t, newTestSQLStore(t))
+	})
+
+	t.Run("when SQLStore fails settling a job from an expired reservation", func(t *testing.T) {
+		testReservation(t, newTestSQLStore(t))
+	})
+
+	t.Run("when SQLStore fails acking a not reserved job", func(t *testing.T) {
+		s := newTestSQLStore(t)
+
+		assert.Nil(s.Enqueue(&Job{ID: "1"}))
+
+		err := s.Ack("1", "")
+		assert.Equal("job '1' not reserved", err.Error())
+	})
+
+	t.Run("when SQLStore fails enqueuing a job twice", func(t *testing.T) {
+		s := newTestSQLStore(t)
+
+		assert.Nil(s.Enqueue(&Job{ID: "1"}))
+		assert.NotNil(s.Enqueue(&Job{ID: "1"}))
+	})
+}
+
+func TestDialect(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when rebind succeed numbering the placeholders", func(t *testing.T) {
+		assert.Equal("SELECT $1, $2", Postgres.rebind("SELECT ?, ?"))
+		assert.Equal("SELECT ?, ?", MySQL.rebind("SELECT ?, ?"))
+	})
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrNoJobs is returned by Reserve when there are no ready jobs.
+var ErrNoJobs = errors.New("no jobs ready")
+
+// Job is a stored job, the job's Runnable is stored encoded as it's type name
+// and payload.
+type Job struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Payload  []byte    `json:"payload"`
+	Enqueued time.Time `json:"enqueued"`
+
+	// Schedule is the job's programmed execution time, the job is ready to be
+	// reserved right away if it's zero.
+	Schedule time.Time `json:"schedule,omitempty"`
+
+	// Reserved is set while the job is reserved by a worker, and Attempts is
+	// the number of times that the job has been reserved.
+	Reserved bool `json:"reserved"`
+	Attempts int  `json:"attempts"`
+
+	// Lease is the reservation's visibility timeout, a reserved job whose
+	// lease has expired is ready to be reserved again.
+	Lease time.Time `json:"lease,omitempty"`
+
+	// Token identifies the job's current reservation, it's set by Reserve and
+	// required to extend, ack, nack or bury the job, so a consumer whose
+	// lease has expired can't settle the job reserved again by another one.
+	Token string `json:"token,omitempty"`
+
+	// Buried is set once the job is moved to the dead letters, and Error is
+	// the error of it's last run.
+	Buried time.Time `json:"buried,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Ready returns true if the job can be reserved at the given time, that's if
+// it isn't reserved or it's lease has expired, and it's schedule has passed.
+// The dead letters are never ready.
+//
+// - now: The reservation time.
+//
+// Returns true if the job is ready.
+func (j *Job) Ready(now time.Time) bool {
+	if !j.Buried.IsZero() {
+		return false
+	}
+
+	if j.Reserved && j.Lease.After(now) {
+		return false
+	}
+
+	return !j.Schedule.After(now)
+}
+
+// Store defines a durable jobs storage with at-least-once delivery. The jobs
+// are enqueued or scheduled, reserved with a visibility timeout to be run and
+// then acked once they are done, or nacked to return them to the store as
+// ready, right away or once a retry delay has passed. A reserved job that is neither acked, nacked or extended before it's
+// visibility timeout expires is returned to the store as ready. The reserved
+// jobs are extended, acked and nacked by their ID and reservation Token, so
+// they fail as not reserved once the job has been reserved again.
+type Store interface {
+	// Enqueue stores a job ready to be reserved.
+	Enqueue(job *Job) error
+
+	// Schedule stores a job that would be ready to be reserved at when.
+	Schedule(job *Job, when time.Time) error
+
+	// Reserve reserves the oldest ready job for the visibility timeout, it
+	// returns ErrNoJobs if none.
+	Reserve(visibility time.Duration) (*Job, error)
+
+	// Extend extends a reserved job's lease by the visibility timeout, as a
+	// heartbeat for the long running jobs.
+	Extend(id, token string, visibility time.Duration) error
+
+	// Ack removes a reserved job from the store.
+	Ack(id, token string) error
+
+	// Nack returns a reserved job to the store, ready to be reserved again
+	// once the delay has passed, right away if it's zero.
+	Nack(id, token string, delay time.Duration) error
+
+	// List returns all the stored jobs, but the dead letters.
+	List() ([]*Job, error)
+}
+
+// Admin defines the stored jobs administration, as the thrall CLI does, and
+// the dead letters, the failed jobs that have reached the max attempts, that
+// the workerPool keeps on the stores that implement it. Every provided Store
+// implements it.
+type Admin interface {
+	// Remove removes a job that is not reserved, as a scheduled job.
+	Remove(id string) error
+
+	// Bury moves a reserved job to the dead letters, with it's last error.
+	Bury(id, token, reason string) error
+
+	// DeadLetters returns the dead letters, the newest first.
+	DeadLetters() ([]*Job, error)
+
+	// Revive returns a dead letter to the store as ready, with it's attempts
+	// reset.
+	Revive(id string) error
+
+	// Purge removes a dead letter.
+	Purge(id string) error
+}
+
+// newToken creates a random reservation token.
+//
+// Returns the hex encoded token.
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// adminStore is a Store that implements Admin, as every provided store does.
+type adminStore interface {
+	Store
+	Admin
+}
+
+// testAdmin tests the Admin implementation of a store.
+func testAdmin(t *testing.T, s adminStore) {
+	assert := assert.New(t)
+
+	assert.Nil(s.Enqueue(&Job{ID: "1", Type: "foo", Payload: []byte(`{}`)}))
+	assert.Nil(s.Schedule(&Job{ID: "2", Type: "foo"}, time.Now().Add(time.Hour)))
+	assert.Nil(s.Enqueue(&Job{ID: "3", Type: "foo"}))
+
+	job, err := s.Reserve(time.Minute)
+	assert.Nil(err)
+	assert.Nil(s.Bury("1", job.Token, "foo failed"))
+
+	assert.Equal("job '1' not reserved", s.Bury("1", job.Token, "foo failed").Error())
+	assert.Equal("job '1' not queued", s.Remove("1").Error())
+
+	jobs, err := s.List()
+	assert.Nil(err)
+	assert.Len(jobs, 2)
+
+	dead, err := s.DeadLetters()
+	assert.Nil(err)
+	assert.Len(dead, 1)
+	assert.Equal("1", dead[0].ID)
+	assert.Equal([]byte(`{}`), dead[0].Payload)
+	assert.Equal(1, dead[0].Attempts)
+	assert.Equal("foo failed", dead[0].Error)
+	assert.False(dead[0].Buried.IsZero())
+
+	assert.Nil(s.Remove("2"))
+	assert.Nil(s.Remove("3"))
+	assert.Equal("job '3' not queued", s.Remove("3").Error())
+
+	_, err = s.Reserve(time.Minute)
+	assert.Equal(ErrNoJobs, err)
+
+	assert.Nil(s.Revive("1"))
+	assert.Equal("job '1' not buried", s.Revive("1").Error())
+
+	job, err = s.Reserve(time.Minute)
+	assert.Nil(err)
+	assert.Equal("1", job.ID)
+	assert.Equal(1, job.Attempts)
+
+	assert.Nil(s.Bury("1", job.Token, "foo failed"))
+	assert.Nil(s.Purge("1"))
+	assert.Equal("job '1' not buried", s.Purge("1").Error())
+
+	dead, err = s.DeadLetters()
+	assert.Nil(err)
+	assert.Empty(dead)
+
+	jobs, err = s.List()
+	assert.Nil(err)
+	assert.Empty(jobs)
+}
+
+// testReservation tests that a store settles the reserved jobs only by their
+// current reservation, so a consumer whose lease has expired can't extend,
+// ack, nack or bury the job reserved again by another one.
+func testReservation(t *testing.T, s adminStore) {
+	assert := assert.New(t)
+
+	assert.Nil(s.Enqueue(&Job{ID: "1"}))
+
+	expired, err := s.Reserve(10 * time.Millisecond)
+	assert.Nil(err)
+	assert.NotEmpty(expired.Token)
+
+	time.Sleep(20 * time.Millisecond)
+
+	job, err := s.Reserve(time.Minute)
+	assert.Nil(err)
+	assert.Equal("1", job.ID)
+	assert.NotEqual(expired.Token, job.Token)
+
+	assert.Equal("job '1' not reserved", s.Extend("1", expired.Token, time.Minute).Error())
+	assert.Equal("job '1' not reserved", s.Ack("1", expired.Token).Error())
+	assert.Equal("job '1' not reserved", s.Nack("1", expired.Token, 0).Error())
+	assert.Equal("job '1' not reserved", s.Bury("1", expired.Token, "foo").Error())
+
+	jobs, err := s.List()
+	assert.Nil(err)
+	assert.Len(jobs, 1)
+	assert.True(jobs[0].Reserved)
+
+	assert.Nil(s.Ack("1", job.Token))
+}
+
+// testRetryDelay tests that a store holds a job nacked with a delay until the
+// delay has passed.
+func testRetryDelay(t *testing.T, s Store) {
+	assert := assert.New(t)
+
+	assert.Nil(s.Enqueue(&Job{ID: "1"}))
+
+	job, err := s.Reserve(time.Minute)
+	assert.Nil(err)
+	assert.Nil(s.Nack("1", job.Token, 50*time.Millisecond))
+
+	_, err = s.Reserve(time.Minute)
+	assert.Equal(ErrNoJobs, err)
+
+	time.Sleep(60 * time.Millisecond)
+
+	job, err = s.Reserve(time.Minute)
+	assert.Nil(err)
+	assert.Equal("1", job.ID)
+	assert.Equal(2, job.Attempts)
+	assert.Nil(s.Ack("1", job.Token))
+}
@@ -0,0 +1,80 @@
+package thrall
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name for thrall's tracer.
+const tracerName = "github.com/jcleira/thrall"
+
+// The job lifecycle phases, each phase is traced as a span that ends when the
+// next phase starts.
+const (
+	phaseEnqueue  = "thrall.enqueue"
+	phaseSchedule = "thrall.schedule"
+	phaseLimiter  = "thrall.limiter"
+	phaseRun      = "thrall.run"
+)
+
+// WithTracerProvider is an optional func for thrall's init, It does configure
+// the OpenTelemetry TracerProvider used to trace the jobs lifecycle. The
+// global TracerProvider is used otherwise.
+//
+// - provider: The OpenTelemetry TracerProvider.
+//
+// Returns a optional configuration function.
+func WithTracerProvider(provider trace.TracerProvider) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.Tracer = provider.Tracer(tracerName)
+	}
+}
+
+// startPhase ends the job's current phase span and starts a new one.
+//
+// - e: The enveloped job.
+// - phase: The new phase name.
+// - attrs: Additional span attributes.
+//
+// Returns the new phase span context.
+func (wp *workerPool) startPhase(e *envelope, phase string, attrs ...attribute.KeyValue) context.Context {
+	wp.endPhase(e)
+
+	attrs = append(attrs,
+		attribute.String("thrall.pool", wp.Name),
+		attribute.String("thrall.job_id", e.id),
+		attribute.String("thrall.job_type", jobType(e.job)),
+	)
+
+	ctx, span := wp.tracer().Start(e.ctx, phase, trace.WithAttributes(attrs...))
+	e.span, e.phase = span, phase
+
+	return ctx
+}
+
+// endPhase ends the job's current phase span, if any.
+//
+// - e: The enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) endPhase(e *envelope) {
+	if e.span != nil {
+		e.span.End()
+		e.span, e.phase = nil, ""
+	}
+}
+
+// tracer returns the workerPool's Tracer, as the workerPool may have been
+// created without Init it defaults to the global TracerProvider's Tracer.
+//
+// Returns the workerPool's Tracer.
+func (wp *workerPool) tracer() trace.Tracer {
+	if wp.Tracer == nil {
+		return otel.Tracer(tracerName)
+	}
+
+	return wp.Tracer
+}
@@ -0,0 +1,84 @@
+package thrall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextualJob struct {
+	JobContext
+	SpanContext trace.SpanContext
+}
+
+func (cj *contextualJob) Run() error {
+	cj.SpanContext = trace.SpanContextFromContext(cj.Context())
+	return nil
+}
+
+type scheduledContextualJob struct {
+	contextualJob
+}
+
+func (sj *scheduledContextualJob) Schedule() time.Time {
+	return time.Now()
+}
+
+func TestTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	t.Run("when a job lifecycle succeed being traced", func(t *testing.T) {
+		exporter.Reset()
+		queue, _, close := Init(1, WithTracerProvider(provider))
+
+		ctx, producer := provider.Tracer("test").Start(context.Background(), "producer")
+
+		job := &contextualJob{}
+		job.WithContext(ctx)
+		queue <- job
+		time.Sleep(10 * time.Millisecond)
+		producer.End()
+
+		spans := exporter.GetSpans()
+		names := make(map[string]tracetest.SpanStub)
+		for _, span := range spans {
+			names[span.Name] = span
+		}
+
+		for _, phase := range []string{phaseEnqueue, phaseLimiter, phaseRun} {
+			assert.Contains(names, phase)
+			assert.Equal(producer.SpanContext().SpanID(), names[phase].Parent.SpanID())
+			assert.Equal(producer.SpanContext().TraceID(), names[phase].SpanContext.TraceID())
+		}
+
+		assert.Equal(names[phaseRun].SpanContext.SpanID(), job.SpanContext.SpanID())
+
+		close <- true
+	})
+
+	t.Run("when a scheduled job lifecycle succeed being traced", func(t *testing.T) {
+		exporter.Reset()
+		queue, _, close := Init(1, WithTracerProvider(provider))
+
+		queue <- &scheduledContextualJob{}
+		time.Sleep(1100 * time.Millisecond)
+
+		names := make(map[string]bool)
+		for _, span := range exporter.GetSpans() {
+			names[span.Name] = true
+		}
+
+		assert.True(names[phaseSchedule])
+		assert.True(names[phaseRun])
+
+		close <- true
+	})
+}
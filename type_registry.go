@@ -0,0 +1,107 @@
+package thrall
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeRegistry maps the job type names to their constructors, so the jobs
+// can be encoded with their type name and decoded back into the right
+// Runnable. Every stored or transmitted job type should be registered.
+type TypeRegistry struct {
+	constructors map[string]func() Runnable
+	names        map[reflect.Type]string
+
+	sync.RWMutex
+}
+
+// NewTypeRegistry creates an empty TypeRegistry.
+//
+// Returns an empty TypeRegistry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		constructors: make(map[string]func() Runnable),
+		names:        make(map[reflect.Type]string),
+	}
+}
+
+// Register adds a job type to the registry.
+//
+// - name: The job type name, as "send_email".
+// - constructor: A func that returns a new empty job of the type, as
+// func() thrall.Runnable { return &SendEmail{} }.
+//
+// Returns an error if the name or the job type are already registered.
+func (tr *TypeRegistry) Register(name string, constructor func() Runnable) error {
+	tr.Lock()
+	defer tr.Unlock()
+
+	if name == "" {
+		return errors.New("job type's name should not be empty")
+	}
+
+	if _, exists := tr.constructors[name]; exists {
+		return fmt.Errorf("job type '%s' already registered", name)
+	}
+
+	t := reflect.TypeOf(constructor())
+	if registered, exists := tr.names[t]; exists {
+		return fmt.Errorf("job type '%s' already registered as '%s'", t, registered)
+	}
+
+	tr.constructors[name] = constructor
+	tr.names[t] = name
+
+	return nil
+}
+
+// New creates a new empty job for the given type name.
+//
+// - name: The job type name.
+//
+// Returns the new job or an error if the type name is not registered.
+func (tr *TypeRegistry) New(name string) (Runnable, error) {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	constructor, exists := tr.constructors[name]
+	if !exists {
+		return nil, fmt.Errorf("job type '%s' not registered", name)
+	}
+
+	return constructor(), nil
+}
+
+// Name returns the registered type name for the given job.
+//
+// - job: The job to get the type name for.
+//
+// Returns the type name or an error if the job type is not registered.
+func (tr *TypeRegistry) Name(job Runnable) (string, error) {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	name, exists := tr.names[reflect.TypeOf(job)]
+	if !exists {
+		return "", fmt.Errorf("job type '%T' not registered", job)
+	}
+
+	return name, nil
+}
+
+// Names returns all the registered type names.
+//
+// Returns the type names.
+func (tr *TypeRegistry) Names() []string {
+	tr.RLock()
+	defer tr.RUnlock()
+
+	names := make([]string, 0, len(tr.constructors))
+	for name := range tr.constructors {
+		names = append(names, name)
+	}
+
+	return names
+}
@@ -0,0 +1,217 @@
+package thrall
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDuplicateJob is returned by Submit when a Unique job is rejected as a
+// duplicate.
+var ErrDuplicateJob = errors.New("duplicate job")
+
+// Unique defines an interface that could be implemented by jobs that must not
+// be duplicated, the UniqueKey() func returns the job's uniqueness key, within
+// it's job type, and UniqueTTL() the max time the key is held. A key is held
+// while it's job is queued, scheduled or running, it's released once the job
+// finishes or after the TTL, if it's not zero, so a stuck job doesn't hold it
+// forever. The duplicates are handled by the workerPool's UniquePolicy.
+type Unique interface {
+	UniqueKey() string
+	UniqueTTL() time.Duration
+}
+
+// UniquePolicy defines how the duplicates of a Unique job are handled, a
+// duplicate of a running job is always rejected.
+type UniquePolicy int
+
+const (
+	// UniqueReject rejects the new job, keeping the one that holds the key.
+	UniqueReject UniquePolicy = iota
+
+	// UniqueReplace cancels the job that holds the key and takes it's place,
+	// so the new job runs on it's own schedule.
+	UniqueReplace
+
+	// UniqueKeepLatest coalesces the new job into the one that holds the key,
+	// which keeps it's ID and place on the queue but runs the new job's
+	// payload. It's not supported with a Store, as the stored job's payload
+	// can't be updated, then the duplicates are rejected.
+	UniqueKeepLatest
+)
+
+// String returns the policy name.
+//
+// Returns the policy name, as "reject".
+func (p UniquePolicy) String() string {
+	switch p {
+	case UniqueReplace:
+		return "replace"
+	case UniqueKeepLatest:
+		return "keep_latest"
+	default:
+		return "reject"
+	}
+}
+
+// uniqueLock is a held uniqueness key.
+type uniqueLock struct {
+	// id is the ID of the job that holds the key and expires the key's TTL
+	// expiration, zero if it doesn't expire.
+	id      string
+	expires time.Time
+
+	// running is set once the job is running, and latest is the payload that
+	// it would run instead, coalesced by UniqueKeepLatest.
+	running bool
+	latest  Runnable
+}
+
+// WithUniquePolicy is an optional func for thrall's init, It does configure
+// how the duplicates of the Unique jobs are handled, they are rejected by
+// default. UniqueKeepLatest with a Store is logged and ignored.
+//
+// - policy: The duplicates policy, check UniquePolicy.
+//
+// Returns a optional configuration function.
+func WithUniquePolicy(policy UniquePolicy) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.UniquePolicy = policy
+	}
+}
+
+// unique acquires a received Unique job's key, or handles the job as a
+// duplicate of the one that holds it.
+//
+// - e: The received enveloped job.
+//
+// Returns the ID of the job that would run the received one, that's it's own
+// ID unless it has been coalesced into a duplicate, or ErrDuplicateJob if it
+// has been rejected.
+func (wp *workerPool) unique(e *envelope) (string, error) {
+	u, ok := e.job.(Unique)
+	if !ok {
+		return e.id, nil
+	}
+
+	key := uniqueKey(e.job)
+	lock := &uniqueLock{id: e.id}
+	if ttl := u.UniqueTTL(); ttl > 0 {
+		lock.expires = time.Now().Add(ttl)
+	}
+
+	wp.uniqueMutex.Lock()
+
+	held, exists := wp.uniques[key]
+	if !exists || (!held.expires.IsZero() && time.Now().After(held.expires)) {
+		wp.uniques[key] = lock
+		wp.uniqueMutex.Unlock()
+
+		return e.id, nil
+	}
+
+	policy := wp.UniquePolicy
+	if held.running {
+		policy = UniqueReject
+	}
+
+	switch policy {
+	case UniqueReplace:
+		wp.uniques[key] = lock
+	case UniqueKeepLatest:
+		held.latest = e.job
+	}
+
+	wp.uniqueMutex.Unlock()
+
+	wp.endPhase(e)
+	wp.IncMetric(e.job, "workerpool_job_duplicated")
+	wp.Logger.Info("job duplicated", jobFields(e,
+		"unique_key", u.UniqueKey(),
+		"duplicate_of", held.id,
+		"policy", policy.String(),
+	)...)
+
+	switch policy {
+	case UniqueReplace:
+		wp.cancel(held.id)
+		return e.id, nil
+	case UniqueKeepLatest:
+		return held.id, nil
+	default:
+		return "", ErrDuplicateJob
+	}
+}
+
+// startUnique marks a Unique job as running, swapping it's payload for the
+// latest coalesced one, it's called right before running the job.
+//
+// - e: The enveloped job to run.
+//
+// Returns nothing.
+func (wp *workerPool) startUnique(e *envelope) {
+	if _, ok := e.job.(Unique); !ok {
+		return
+	}
+
+	wp.uniqueMutex.Lock()
+	defer wp.uniqueMutex.Unlock()
+
+	lock, exists := wp.uniques[uniqueKey(e.job)]
+	if !exists || lock.id != e.id {
+		return
+	}
+
+	lock.running = true
+	if lock.latest != nil {
+		e.job, lock.latest = lock.latest, nil
+	}
+}
+
+// stopUnique marks a Unique job as not running, as it's returned to the queue
+// without being completed, so it's duplicates are handled by the
+// UniquePolicy again.
+//
+// - e: The enveloped job returned to the queue.
+//
+// Returns nothing.
+func (wp *workerPool) stopUnique(e *envelope) {
+	if _, ok := e.job.(Unique); !ok {
+		return
+	}
+
+	wp.uniqueMutex.Lock()
+	defer wp.uniqueMutex.Unlock()
+
+	if lock, exists := wp.uniques[uniqueKey(e.job)]; exists && lock.id == e.id {
+		lock.running = false
+	}
+}
+
+// releaseUnique releases a finished Unique job's key, unless it's held by
+// another job.
+//
+// - e: The finished enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) releaseUnique(e *envelope) {
+	if _, ok := e.job.(Unique); !ok {
+		return
+	}
+
+	wp.uniqueMutex.Lock()
+	defer wp.uniqueMutex.Unlock()
+
+	key := uniqueKey(e.job)
+	if lock, exists := wp.uniques[key]; exists && lock.id == e.id {
+		delete(wp.uniques, key)
+	}
+}
+
+// uniqueKey returns a Unique job's key scoped by it's job type.
+//
+// - job: The Unique job.
+//
+// Returns the scoped key.
+func uniqueKey(job Runnable) string {
+	return jobType(job) + "/" + job.(Unique).UniqueKey()
+}
@@ -0,0 +1,175 @@
+package thrall
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jcleira/thrall/metrics"
+	"github.com/jcleira/thrall/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type uniqueJob struct {
+	Key     string
+	Value   string
+	TTL     time.Duration
+	runs    chan string
+	release chan bool
+}
+
+func (uj *uniqueJob) Run() error {
+	if uj.release != nil {
+		<-uj.release
+	}
+
+	if uj.runs != nil {
+		uj.runs <- uj.Value
+	}
+
+	return nil
+}
+
+func (uj *uniqueJob) UniqueKey() string {
+	return uj.Key
+}
+
+func (uj *uniqueJob) UniqueTTL() time.Duration {
+	return uj.TTL
+}
+
+type scheduledUniqueJob struct {
+	uniqueJob
+}
+
+func (sj *scheduledUniqueJob) Schedule() time.Time {
+	return time.Now().Add(time.Hour)
+}
+
+func TestUnique(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Unique succeed rejecting the duplicates", func(t *testing.T) {
+		registerer := prometheus.NewRegistry()
+		registry := metrics.NewRegistry(metrics.WithRegisterer(registerer))
+
+		_, _, close := Init(1, WithMetricsRegistry(registry))
+		defer func() { close <- true }()
+
+		_, err := Submit(&scheduledUniqueJob{uniqueJob{Key: "foo"}})
+		assert.Nil(err)
+
+		_, err = Submit(&scheduledUniqueJob{uniqueJob{Key: "foo"}})
+		assert.Equal(ErrDuplicateJob, err)
+
+		_, err = Submit(&scheduledUniqueJob{uniqueJob{Key: "bar"}})
+		assert.Nil(err)
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(2, Stats().Scheduled)
+
+		families, err := registerer.Gather()
+		assert.Nil(err)
+
+		duplicated := 0.0
+		for _, family := range families {
+			if family.GetName() == "thrall_workerpool_job_duplicated" {
+				duplicated = family.GetMetric()[0].GetCounter().GetValue()
+			}
+		}
+		assert.Equal(1.0, duplicated)
+	})
+
+	t.Run("when Unique succeed replacing the queued duplicate", func(t *testing.T) {
+		_, _, close := Init(1, WithUniquePolicy(UniqueReplace))
+		defer func() { close <- true }()
+
+		first, _ := Submit(&scheduledUniqueJob{uniqueJob{Key: "foo"}})
+		time.Sleep(10 * time.Millisecond)
+
+		second, err := Submit(&scheduledUniqueJob{uniqueJob{Key: "foo"}})
+		assert.Nil(err)
+		assert.NotEqual(first, second)
+
+		time.Sleep(10 * time.Millisecond)
+
+		status, _ := Status(first)
+		assert.Equal(StateCanceled, status.State)
+		assert.Equal(1, Stats().Scheduled)
+	})
+
+	t.Run("when Unique succeed keeping the latest payload", func(t *testing.T) {
+		_, _, close := Init(1, WithUniquePolicy(UniqueKeepLatest))
+		defer func() { close <- true }()
+
+		runs := make(chan string, 2)
+
+		Pause()
+		first, _ := Submit(&uniqueJob{Key: "foo", Value: "first", runs: runs})
+		time.Sleep(10 * time.Millisecond)
+
+		second, err := Submit(&uniqueJob{Key: "foo", Value: "second", runs: runs})
+		assert.Nil(err)
+		assert.Equal(first, second)
+
+		Resume()
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Len(runs, 1)
+		assert.Equal("second", <-runs)
+	})
+
+	t.Run("when UniqueKeepLatest is ignored due a Store", func(t *testing.T) {
+		fs, err := store.NewFileStore(filepath.Join(t.TempDir(), "jobs.log"))
+		assert.Nil(err)
+		defer fs.Close()
+
+		_, _, close := Init(1, WithStore(fs, storedJobCodec{}),
+			WithUniquePolicy(UniqueKeepLatest))
+
+		assert.Equal(UniqueReject, wp.UniquePolicy)
+
+		close <- true
+	})
+
+	t.Run("when Unique succeed releasing the key once the job finishes", func(t *testing.T) {
+		_, _, close := Init(1, WithUniquePolicy(UniqueKeepLatest))
+		defer func() { close <- true }()
+
+		release := make(chan bool)
+		Submit(&uniqueJob{Key: "foo", release: release})
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := Submit(&uniqueJob{Key: "foo"})
+		assert.Equal(ErrDuplicateJob, err)
+
+		release <- true
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = Submit(&uniqueJob{Key: "foo"})
+		assert.Nil(err)
+	})
+
+	t.Run("when Unique succeed releasing the key after it's TTL", func(t *testing.T) {
+		_, _, close := Init(1)
+		defer func() { close <- true }()
+
+		Submit(&scheduledUniqueJob{uniqueJob{Key: "foo", TTL: 10 * time.Millisecond}})
+		time.Sleep(20 * time.Millisecond)
+
+		_, err := Submit(&scheduledUniqueJob{uniqueJob{Key: "foo"}})
+		assert.Nil(err)
+	})
+
+	t.Run("when Unique succeed dropping the duplicates sent to the queue", func(t *testing.T) {
+		queue, _, close := Init(1)
+		defer func() { close <- true }()
+
+		queue <- &scheduledUniqueJob{uniqueJob{Key: "foo"}}
+		queue <- &scheduledUniqueJob{uniqueJob{Key: "foo"}}
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal(1, Stats().Scheduled)
+	})
+}
@@ -1,8 +1,11 @@
 package thrall
 
 import (
-	"fmt"
+	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // jobTimeout is the defined max time for a job to be executed on the worker.
@@ -14,10 +17,12 @@ const jobTimeout = 30 * time.Second
 // instead we run the jobs by attaching them to a limited number of workers.
 type worker struct {
 	Id         int
-	Queue      chan Runnable
-	Errors     chan error
+	Queue      chan *envelope
 	Close      chan bool
 	workerPool *workerPool
+
+	// retire is closed to retire the worker once it finish it's current job.
+	retire chan bool
 }
 
 // Worker Start starts the worker goroutine and becomes ready to accept Jobs.
@@ -27,10 +32,15 @@ func (w *worker) Start() {
 	go func() {
 		for {
 			select {
-			case job := <-w.Queue:
-				w.workerPool.IncMetric("thrall_workerpool_job_enqueued")
-				w.Enqueue(job)
-				w.workerPool.DecMetric("thrall_workerpool_job_enqueued")
+			case e := <-w.Queue:
+				w.workerPool.queued.Add(-1)
+				w.workerPool.busy.Add(1)
+				w.workerPool.IncMetric(e.job, "workerpool_job_enqueued")
+				w.Enqueue(e)
+				w.workerPool.DecMetric(e.job, "workerpool_job_enqueued")
+				w.workerPool.busy.Add(-1)
+			case <-w.retire:
+				return
 			case <-w.Close:
 				return
 			}
@@ -41,25 +51,51 @@ func (w *worker) Start() {
 // Enqueue feeds the worker with a job, but it might don't ingest it under under
 // some circunstances as hiting the configured limiter's limit.
 //
-// - job: The Runnable to enqueue on the worker.
+// - e: The enveloped Runnable to enqueue on the worker.
 //
 // Returns nothing.
-func (w *worker) Enqueue(job Runnable) {
+func (w *worker) Enqueue(e *envelope) {
+	if w.workerPool.skipCanceled(e) {
+		return
+	}
+
+	if e.phase != phaseLimiter {
+		w.workerPool.startPhase(e, phaseLimiter)
+	}
+
 	if !w.workerPool.Limiter.Adquire() {
-		w.workerPool.IncMetric("thrall_workerpool_job_rate_limited")
-		go func() {
-			w.workerPool.Queue <- job
-		}()
+		w.workerPool.IncMetric(e.job, "workerpool_job_rate_limited")
+		e.span.AddEvent("thrall.rate_limited")
+		w.workerPool.Logger.Debug("job rate limited", jobFields(e,
+			"worker_id", w.Id,
+			"limiter", w.workerPool.Limiter.Stats().Name,
+		)...)
+		w.workerPool.Hooks.retry(e.job)
+		go w.workerPool.dispatch(e)
 
 		return
 	}
 
-	w.Run(job)
+	wait := time.Since(e.ready)
+	w.workerPool.ObserveMetric(e.job, "job_wait_seconds", wait.Seconds())
+	w.workerPool.observeWait(wait)
+	if !e.schedule.IsZero() {
+		w.workerPool.ObserveMetric(e.job, "job_schedule_lateness_seconds",
+			time.Since(e.schedule).Seconds())
+	}
+
+	w.workerPool.startUnique(e)
+	err := w.Run(e)
 	w.workerPool.Limiter.Release()
+	canceled := w.workerPool.finishStatus(e, err, err != nil && w.workerPool.retryable(e))
+
+	if e.stored {
+		w.workerPool.settle(e, err == nil || canceled)
+	}
 
-	if repeatable, ok := job.(Repeateable); ok {
+	if repeatable, ok := e.job.(Repeateable); ok {
 		if repeatable.Repeat() {
-			w.workerPool.Queue <- job
+			w.workerPool.Queue <- e.job
 		}
 	}
 }
@@ -68,26 +104,72 @@ func (w *worker) Enqueue(job Runnable) {
 // time is hit, Run free the worker to accept more Jobs but as it launches a
 // new goroutine to execute the Runnable that goroutine may get leaked.
 //
-// - job: The Runnable to run on the worker.
+// - e: The enveloped Runnable to run on the worker.
 //
-// Returns nothing.
-func (w *worker) Run(job Runnable) {
-	done := make(chan bool)
+// Returns the job's error, or a timeout JobError, nil if the job succeed.
+func (w *worker) Run(e *envelope) error {
+	job := e.job
+
+	ctx := w.workerPool.startPhase(e, phaseRun, attribute.Int("thrall.worker_id", w.Id))
+	span := e.span
+	defer w.workerPool.endPhase(e)
+
+	ctx, release := w.workerPool.runContext(ctx, e)
+	defer release()
+
+	w.workerPool.Logger.Debug("job started", jobFields(e, "worker_id", w.Id)...)
+	w.workerPool.Hooks.start(job)
+	w.workerPool.startStatus(e, strconv.Itoa(w.Id))
+
+	done := make(chan error, 1)
 	go func() {
-		if err := job.Run(); err != nil {
-			w.workerPool.IncMetric("thrall_workerpool_job_erroed")
-			w.Errors <- fmt.Errorf("job error on worker %d. Err: %v", w.Id, err)
+		start := time.Now()
+		err := w.workerPool.handler(ctx, job)
+		duration := time.Since(start)
+		w.workerPool.ObserveMetric(job, "job_run_seconds", duration.Seconds())
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			w.workerPool.Logger.Error("job failed", jobFields(e,
+				"worker_id", w.Id,
+				"duration", duration,
+				"error", err,
+			)...)
+			w.workerPool.IncMetric(job, "workerpool_job_erroed")
+
+			je := newJobError(e, w.Id)
+			je.Err = err
+			w.workerPool.Hooks.failure(job, je)
+			w.workerPool.reportError(je)
+		} else {
+			w.workerPool.Hooks.success(job)
+			w.workerPool.Logger.Debug("job finished", jobFields(e,
+				"worker_id", w.Id,
+				"duration", duration,
+			)...)
 		}
 
-		w.workerPool.IncMetric("thrall_workerpool_job_processed")
-		done <- true
+		w.workerPool.IncMetric(job, "workerpool_job_processed")
+		done <- err
 	}()
 
 	select {
 	case <-time.After(jobTimeout):
-		w.workerPool.IncMetric("thrall_workerpool_job_timeout")
-		w.Errors <- fmt.Errorf("job timeout (%f sec) on worker %d", jobTimeout.Seconds(), w.Id)
-	case <-done:
-	}
+		span.SetStatus(codes.Error, "job timeout")
+		w.workerPool.Logger.Error("job timeout", jobFields(e,
+			"worker_id", w.Id,
+			"timeout", jobTimeout,
+		)...)
+		w.workerPool.IncMetric(job, "workerpool_job_timeout")
 
+		je := newJobError(e, w.Id)
+		je.Timeout = true
+		w.workerPool.Hooks.failure(job, je)
+		w.workerPool.reportError(je)
+
+		return je
+	case err := <-done:
+		return err
+	}
 }
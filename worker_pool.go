@@ -6,11 +6,17 @@ package thrall
 // Returns nothing.
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jcleira/thrall/limiters"
 	"github.com/jcleira/thrall/metrics"
+	"github.com/jcleira/thrall/store"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // workerPool defines a group of workers and their common characteristics. The
@@ -19,27 +25,125 @@ import (
 // Limiters would set a group for rules for all the workers on the pool to
 // follow.
 type workerPool struct {
+	// Name is the workerPool name, It's used to label the workerPool metrics.
+	Name string
+
 	// Queue is the main Jobs Queue, It contains all the jobs that are waiting to
 	// be run.
 	Queue chan Runnable
 
 	// Delayed is the Jobs Scheduled Queue, It containes all the scheduled jobs
 	// that are waiting for their execution time.
-	Delayed       map[time.Time][]Runnable
+	Delayed       map[time.Time][]*envelope
 	DelayedMutext sync.Mutex
 
 	// Limiter is the workerPool configured Jobs limiter, currently only one
 	// limiter can be configured per workerPool.
 	Limiter limiters.Limiter
 
-	// Metrics is the workerPool metrics container. It has been created to
-	// collect and report jobs related metrics, that will be exposed ready to be
-	// scrapped on prometheus.
-	Metrics *metrics.Registry
-
-	workersQueue chan Runnable
+	// Metrics is the workerPool metrics sink. It has been created to collect
+	// and report jobs related metrics to any metrics backend, as prometheus,
+	// StatsD or expvar. It discards every metric by default.
+	Metrics metrics.Sink
+
+	// JobTypes is the allowlist of job types used to label the jobs metrics, any
+	// other job type would be labeled as "other" to keep the metrics cardinality
+	// under control. All job types are allowed if it's empty.
+	JobTypes map[string]bool
+
+	// Tracer is the workerPool OpenTelemetry tracer, It traces every job
+	// lifecycle phase as a span: enqueue, schedule, limiter and run.
+	Tracer trace.Tracer
+
+	// Logger is the workerPool structured logger, It logs every job lifecycle
+	// event. It discards every event by default.
+	Logger Logger
+
+	// Hooks are the workerPool lifecycle callbacks.
+	Hooks Hooks
+
+	// middlewares is the jobs Middleware chain, and handler the Handler that
+	// runs every job through it.
+	middlewares []Middleware
+	handler     Handler
+
+	// onError is the job errors handler, errorsBuffered is set if the errors
+	// channel never blocks and errorsDropped counts the errors that didn't fit
+	// on it.
+	onError        func(JobError)
+	errorsBuffered bool
+	errorsDropped  atomic.Uint64
+
+	// Store is the workerPool durable jobs storage, and Codec the jobs encoder
+	// for it. The jobs are kept only in memory if it's nil.
+	Store store.Store
+	Codec Codec
+
+	// VisibilityTimeout is the lease of the jobs reserved from the Store,
+	// MaxAttempts the max number of times that a failing stored job is run
+	// and RetryBackoff the time that it waits before it's first retry.
+	VisibilityTimeout time.Duration
+	MaxAttempts       int
+	RetryBackoff      time.Duration
+
+	// Autoscaler is the workerPool autoscaling rules, the workerPool keeps
+	// it's initial number of workers if it's nil.
+	Autoscaler *Autoscaler
+
+	// queued is the number of jobs waiting for a free worker, busy the number
+	// of workers running a job and maxWait the longest time that a job have
+	// been waiting for a free worker since the last autoscaler check.
+	queued  atomic.Int64
+	busy    atomic.Int64
+	maxWait atomic.Int64
+
+	// paused is set while the workerPool is paused, pausedTypes contains the
+	// paused job types and held the jobs received while paused.
+	paused      bool
+	pausedTypes map[string]bool
+	held        []*envelope
+	pauseMutex  sync.Mutex
+
+	// statuses are the tracked jobs statuses, finished the finished jobs IDs,
+	// oldest first, dead the failed jobs statuses, and statusHistory how many
+	// finished jobs and dead letters are kept. watchers are called once their
+	// job is finished, and changes queue every status change for WatchStatus.
+	statuses      map[string]*JobStatus
+	watchers      map[string][]func(JobStatus)
+	changes       map[string][]*statusChanges
+	canceled      map[string]bool
+	cancels       map[string]context.CancelFunc
+	finished      []string
+	dead          []deadLetter
+	statusHistory int
+	statusMutex   sync.Mutex
+
+	// UniquePolicy is how the Unique jobs duplicates are handled, and uniques
+	// the held uniqueness keys.
+	UniquePolicy UniquePolicy
+	uniques      map[string]*uniqueLock
+	uniqueMutex  sync.Mutex
+
+	// delays are the debounced and throttled keys states.
+	delays     map[string]*delayedKey
+	delayMutex sync.Mutex
+
+	// remoteLeases are the jobs leased to remote workers by their ID, it's
+	// nil unless the remote workers are enabled.
+	remoteLeases map[string]*remoteLease
+	remoteMutex  sync.Mutex
+
+	// submitted is the queue of the submitted jobs waiting to be received,
+	// and submitBuffer it's size.
+	submitted    chan *envelope
+	submitBuffer int
+
+	stored       chan bool
+	workersQueue chan *envelope
 	workersClose chan bool
 	workers      []*worker
+	workersMutex sync.Mutex
+	lastWorkerID int
 	errors       chan error
 	close        chan bool
 }
@@ -58,12 +162,29 @@ var wp *workerPool
 // Returns the jobs queue, an errors channel and close channel.
 func Init(workers int, opts ...func(*workerPool)) (chan Runnable, chan error, chan bool) {
 	wp = &workerPool{
-		Queue:        make(chan Runnable),
-		Delayed:      make(map[time.Time][]Runnable),
-		close:        make(chan bool),
-		errors:       make(chan error),
-		workersQueue: make(chan Runnable),
-		workersClose: make(chan bool),
+		Name:              "default",
+		Metrics:           metrics.Nop{},
+		Tracer:            otel.Tracer(tracerName),
+		Logger:            NopLogger{},
+		VisibilityTimeout: defaultVisibilityTimeout,
+		MaxAttempts:       defaultMaxAttempts,
+		RetryBackoff:      defaultRetryBackoff,
+		Queue:             make(chan Runnable),
+		Delayed:           make(map[time.Time][]*envelope),
+		pausedTypes:       make(map[string]bool),
+		statuses:          make(map[string]*JobStatus),
+		canceled:          make(map[string]bool),
+		cancels:           make(map[string]context.CancelFunc),
+		watchers:          make(map[string][]func(JobStatus)),
+		changes:           make(map[string][]*statusChanges),
+		uniques:           make(map[string]*uniqueLock),
+		delays:            make(map[string]*delayedKey),
+		statusHistory:     defaultStatusHistory,
+		submitBuffer:      defaultSubmitBuffer,
+		close:             make(chan bool),
+		errors:            make(chan error),
+		workersQueue:      make(chan *envelope),
+		workersClose:      make(chan bool),
 	}
 
 	for _, option := range opts {
@@ -71,20 +192,44 @@ func Init(workers int, opts ...func(*workerPool)) (chan Runnable, chan error, ch
 	}
 
 	// TODO we are forcing one limiter, we might force the user to send it.
-	if len(opts) == 0 {
+	if wp.Limiter == nil {
 		wp.Limiter = &limiters.Max{Max: 1000}
 	}
 
-	for i := 1; i <= workers; i++ {
-		worker := &worker{
-			Id:         i,
-			workerPool: wp,
-			Queue:      wp.workersQueue,
-			Errors:     wp.errors,
-			Close:      wp.workersClose,
+	wp.registerPoolMetrics()
+	wp.handler = chain(wp.middlewares)
+
+	if wp.submitBuffer < 1 {
+		wp.Logger.Error("submit buffer ignored", "pool", wp.Name, "size", wp.submitBuffer)
+		wp.submitBuffer = defaultSubmitBuffer
+	}
+	wp.submitted = make(chan *envelope, wp.submitBuffer)
+
+	if wp.UniquePolicy == UniqueKeepLatest && wp.Store != nil {
+		wp.Logger.Error("unique policy not supported with a store, ignored",
+			"pool", wp.Name, "policy", wp.UniquePolicy)
+		wp.UniquePolicy = UniqueReject
+	}
+
+	if wp.Autoscaler != nil {
+		if err := wp.Autoscaler.validate(wp.remoteLeases != nil); err != nil {
+			wp.Logger.Error("autoscaler ignored", "pool", wp.Name, "error", err)
+			wp.Autoscaler = nil
+		}
+	}
+
+	if wp.Autoscaler != nil {
+		if workers < wp.Autoscaler.Min {
+			workers = wp.Autoscaler.Min
 		}
 
-		wp.workers = append(wp.workers, worker)
+		if workers > wp.Autoscaler.Max {
+			workers = wp.Autoscaler.Max
+		}
+	}
+
+	for i := 1; i <= workers; i++ {
+		wp.workers = append(wp.workers, wp.newWorker())
 	}
 
 	wp.run()
@@ -118,29 +263,137 @@ func WithPerSecondLimiter(perSecondJobs int) func(*workerPool) {
 	}
 }
 
+// WithName is an optional func for thrall's init, It does configure the
+// workerPool name that would be used to label the workerPool metrics.
+//
+// - name: The workerPool name.
+//
+// Returns a optional configuration function.
+func WithName(name string) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.Name = name
+	}
+}
+
+// WithJobTypes is an optional func for thrall's init, It does configure the
+// allowlist of job types that would be used to label the jobs metrics, any
+// job which type is not on the allowlist would be labeled as "other".
+//
+// - types: The allowed job types, check the Named interface.
+//
+// Returns a optional configuration function.
+func WithJobTypes(types ...string) func(*workerPool) {
+	return func(wp *workerPool) {
+		wp.JobTypes = make(map[string]bool)
+		for _, t := range types {
+			wp.JobTypes[t] = true
+		}
+	}
+}
+
 // WithMetrics is an optional func for thrall's init, It does configure a
 // internal prometheus metrics system that would report workerpool and worker
-// stas on the /metrics endpoint.
+// stats on the prometheus global registry, under the "thrall" namespace. The
+// metrics are not served, use promhttp.Handler() on your own http server.
+//
+// - buckets: Optional jobs latency histograms upper bounds, in seconds.
+//
+// Returns a optional configuration function.
+func WithMetrics(buckets ...float64) func(*workerPool) {
+	return WithMetricsRegistry(metrics.NewRegistry(), buckets...)
+}
+
+// WithMetricsRegistry is an optional func for thrall's init, It does configure
+// the given metrics registry to report workerpool and worker stats, use it to
+// set your own prometheus Registerer and namespace, then mount the registry's
+// Handler to expose them.
+//
+// - registry: The metrics registry, check metrics.NewRegistry.
+// - buckets: Optional jobs latency histograms upper bounds, in seconds.
 //
 // Returns a optional configuration function.
-func WithMetrics() func(*workerPool) {
+func WithMetricsRegistry(registry *metrics.Registry, buckets ...float64) func(*workerPool) {
+	return WithMetricsSink(registry, buckets...)
+}
+
+// WithMetricsSink is an optional func for thrall's init, It does configure the
+// given metrics sink to report workerpool and worker stats, use the
+// metrics.StatsD and metrics.Expvar sinks to report them elsewhere than
+// prometheus. The sinks name the metrics under the "thrall" namespace by
+// default, as WithMetrics does.
+//
+// - sink: The metrics sink, check metrics.Sink.
+// - buckets: Optional jobs latency histograms upper bounds, in seconds.
+//
+// Returns a optional configuration function.
+func WithMetricsSink(sink metrics.Sink, buckets ...float64) func(*workerPool) {
 	return func(wp *workerPool) {
-		wp.Metrics = metrics.NewRegistry()
-		wp.Metrics.NewGauges(
-			"thrall_workerpool_job_enqueued",
-			"thrall_workerpool_job_scheduled",
+		labels := []string{"pool", "job_type"}
+
+		wp.Metrics = sink
+		wp.Metrics.NewGaugeVecs(labels,
+			"workerpool_job_enqueued",
+			"workerpool_job_scheduled",
+			"workerpool_job_held",
+		)
+
+		wp.Metrics.NewCounterVecs(labels,
+			"workerpool_job_processed",
+			"workerpool_job_received",
+			"workerpool_job_erroed",
+			"workerpool_job_timeout",
+			"workerpool_job_rate_limited",
+			"workerpool_job_errors_dropped",
+			"workerpool_job_dropped",
+			"workerpool_job_rejected",
+			"workerpool_job_lease_expired",
+			"workerpool_job_duplicated",
+			"workerpool_job_debounced",
+			"workerpool_job_throttled",
 		)
 
-		wp.Metrics.NewCounters(
-			"thrall_workerpool_job_processed",
-			"thrall_workerpool_job_received",
-			"thrall_workerpool_job_erroed",
-			"thrall_workerpool_job_timeout",
-			"thrall_workerpool_job_rate_limited",
+		wp.Metrics.NewCounterVecs([]string{"pool"},
+			"workerpool_scaled_up",
+			"workerpool_scaled_down",
+		)
+
+		wp.Metrics.NewHistogramVecs(buckets, labels,
+			"job_wait_seconds",
+			"job_run_seconds",
+			"job_schedule_lateness_seconds",
 		)
 	}
 }
 
+// registerPoolMetrics exposes the workerPool number of workers and the
+// configured limiter's Stats as metrics, labeled by the limiter name, the
+// values are read on every scrape.
+//
+// Returns nothing.
+func (wp *workerPool) registerPoolMetrics() {
+	wp.Metrics.NewGaugeFunc("workerpool_workers", map[string]string{"pool": wp.Name},
+		func() float64 {
+			return float64(wp.size())
+		})
+
+	labels := map[string]string{
+		"pool":    wp.Name,
+		"limiter": wp.Limiter.Stats().Name,
+	}
+
+	wp.Metrics.NewGaugeFunc("limiter_in_use", labels, func() float64 {
+		return float64(wp.Limiter.Stats().InUse)
+	})
+
+	wp.Metrics.NewGaugeFunc("limiter_capacity", labels, func() float64 {
+		return float64(wp.Limiter.Stats().Capacity)
+	})
+
+	wp.Metrics.NewCounterFunc("limiter_denied", labels, func() float64 {
+		return float64(wp.Limiter.Stats().Denied)
+	})
+}
+
 // run would launch the workerPool by starting all it's workers.
 //
 // Returns nothing.
@@ -151,16 +404,20 @@ func (wp *workerPool) run() {
 		for {
 			select {
 			case job := <-wp.Queue:
-				wp.IncMetric("thrall_workerpool_job_received")
+				e := wp.newEnvelope(job)
+				if id, err := wp.unique(e); err != nil || id != e.id {
+					continue
+				}
 
-				if scheduleable, ok := job.(Scheduleable); ok {
-					wp.IncMetric("thrall_workerpool_job_scheduled")
-					go wp.schedule(job, scheduleable.Schedule())
+				if wp.delay(e) != e.id {
 					continue
 				}
 
-				wp.workersQueue <- job
+				wp.track(e)
+				wp.receive(e)
 			case <-wp.close:
+				wp.Logger.Info("workerpool shutdown", "pool", wp.Name)
+				wp.discardPending()
 				close(wp.workersClose)
 				close(wp.close)
 				return
@@ -168,79 +425,285 @@ func (wp *workerPool) run() {
 		}
 	}()
 
+	go wp.receiveSubmitted()
+
 	go func() {
 		for {
 			wp.enqueueScheduled()
+			wp.expireDelayed()
 			time.Sleep(time.Second)
 		}
 	}()
 
+	wp.workersMutex.Lock()
 	for i := 0; i < len(wp.workers); i++ {
 		wp.workers[i].Start()
 	}
+	wp.workersMutex.Unlock()
+
+	if wp.Autoscaler != nil {
+		go wp.autoscale()
+	}
+
+	if wp.Store != nil {
+		go wp.feed()
+	}
+
+	if wp.remoteLeases != nil {
+		go wp.reapLeases()
+	}
+
+	wp.Logger.Info("workerpool started", "pool", wp.Name, "workers", wp.size())
+}
+
+// receive handles a received job, the job is stored if the workerPool has a
+// Store, scheduled if it's Scheduleable, debounced or throttled, or dispatched
+// to the workers.
+//
+// - e: The received enveloped job.
+//
+// Returns nothing.
+func (wp *workerPool) receive(e *envelope) {
+	job := e.job
+
+	wp.IncMetric(job, "workerpool_job_received")
+	wp.Logger.Debug("job received", jobFields(e)...)
+	wp.Hooks.enqueue(job)
+
+	if wp.Store != nil && e.due.IsZero() && wp.persist(e) {
+		return
+	}
+
+	if !e.due.IsZero() {
+		wp.IncMetric(job, "workerpool_job_scheduled")
+		go wp.schedule(e, e.due)
+		return
+	}
+
+	if scheduleable, ok := job.(Scheduleable); ok {
+		wp.IncMetric(job, "workerpool_job_scheduled")
+		go wp.schedule(e, scheduleable.Schedule())
+		return
+	}
+
+	e.ready = time.Now()
+	wp.dispatch(e)
 }
 
 // schedule performs job scheduling for thrall's scheduleable job interfaces
 //
-// - job: The job to schedule.
+// - e: The enveloped job to schedule.
 // - when: The job programmed execution time.
 //
 // Returns nothing.
-func (wp *workerPool) schedule(job Runnable, when time.Time) {
+func (wp *workerPool) schedule(e *envelope, when time.Time) {
 	wp.DelayedMutext.Lock()
 	defer wp.DelayedMutext.Unlock()
 
-	if delayed, ok := wp.Delayed[when]; ok {
-		delayed = append(delayed, job)
-	} else {
-		wp.Delayed[when] = []Runnable{job}
-	}
+	wp.startPhase(e, phaseSchedule,
+		attribute.String("thrall.schedule", when.Format(time.RFC3339Nano)))
+
+	wp.Delayed[when] = append(wp.Delayed[when], e)
+	wp.Logger.Debug("job scheduled", jobFields(e, "schedule", when)...)
 }
 
 // enqueueScheduled handle the enqueing for thrall's scheduled jobs, It ticks
-// on every second to check for enqueable scheduled jobs.
+// on every second to check for enqueable scheduled jobs. The paused jobs are
+// kept on the scheduler until resumed, and the debounced ones until their
+// key's last submission delay has passed. The due jobs are dispatched once
+// the scheduler is unlocked, as dispatching blocks until a worker takes them.
 //
 // Returns nothing
 func (wp *workerPool) enqueueScheduled() {
+	for _, e := range wp.dueScheduled() {
+		wp.dispatch(e)
+	}
+}
+
+// dueScheduled takes the scheduled jobs whose execution time has passed out
+// of the scheduler, the paused and debounced ones are kept on it.
+//
+// Returns the due enveloped jobs.
+func (wp *workerPool) dueScheduled() []*envelope {
 	wp.DelayedMutext.Lock()
 	defer wp.DelayedMutext.Unlock()
 
-	for schedule, jobs := range wp.Delayed {
+	var due []*envelope
+	for schedule, envelopes := range wp.Delayed {
 		if time.Now().After(schedule) {
-			for _, job := range jobs {
-				if wp.Metrics != nil {
-					wp.DecMetric("thrall_workerpool_job_scheduled")
+			var paused []*envelope
+
+			for _, e := range envelopes {
+				if wp.isPaused(e.job) {
+					paused = append(paused, e)
+					continue
+				}
+
+				if delayed, debounced := wp.dueDelayed(e); debounced {
+					wp.Delayed[delayed] = append(wp.Delayed[delayed], e)
+					continue
 				}
-				wp.workersQueue <- job
+
+				wp.DecMetric(e.job, "workerpool_job_scheduled")
+				wp.startPhase(e, phaseEnqueue)
+				wp.Logger.Debug("scheduled job enqueued", jobFields(e, "schedule", schedule)...)
+
+				e.ready, e.schedule = time.Now(), schedule
+				wp.updateStatus(e, func(status *JobStatus) {
+					status.State = StateQueued
+				})
+				due = append(due, e)
+			}
+
+			if len(paused) > 0 {
+				wp.Delayed[schedule] = paused
+				continue
 			}
 
 			delete(wp.Delayed, schedule)
 		}
 	}
+
+	return due
 }
 
-// IncMetric increments any given metric, actually it's a wrapper func to avoid
-// checking if the metrics registry is nil everytime that we want to report a
-// value.
+// newWorker creates a new worker for the workerPool, the worker is not
+// started.
 //
-// - metrics: THe metrics to increment.
+// Returns the new worker.
+func (wp *workerPool) newWorker() *worker {
+	wp.lastWorkerID++
+
+	return &worker{
+		Id:         wp.lastWorkerID,
+		workerPool: wp,
+		Queue:      wp.workersQueue,
+		Close:      wp.workersClose,
+		retire:     make(chan bool),
+	}
+}
+
+// dispatch sends an enveloped job to the workers, blocking until a worker
+// takes it or the workerPool is closed, paused jobs are held until resumed.
+//
+// - e: The enveloped job to dispatch.
 //
 // Returns nothing.
-func (wp *workerPool) IncMetric(metrics ...string) {
-	if wp.Metrics != nil {
-		wp.Metrics.Inc(metrics...)
+func (wp *workerPool) dispatch(e *envelope) {
+	if wp.hold(e) {
+		return
+	}
+
+	wp.queued.Add(1)
+
+	select {
+	case wp.workersQueue <- e:
+	case <-wp.workersClose:
+		wp.queued.Add(-1)
+		wp.discard(e)
 	}
 }
 
-// DecMetric decrements any given metric, actually it's a wrapper func to avoid
-// checking if the metrics registry is nil everytime that we want to report a
+// discard drops a job that won't be run as the workerPool is closed, the
+// stored jobs are returned to the Store.
+//
+// - e: The enveloped job to discard.
+//
+// Returns nothing.
+func (wp *workerPool) discard(e *envelope) {
+	wp.endPhase(e)
+
+	if e.stored {
+		wp.nack(e, 0)
+		return
+	}
+
+	wp.Logger.Debug("job discarded", jobFields(e)...)
+	wp.Hooks.discard(e.job)
+	wp.discardStatus(e)
+}
+
+// discardPending discards all the scheduled jobs that are still waiting for
+// their execution time and all the held paused jobs, it's called on shutdown
+// as no worker would run them. The stored jobs are returned to the Store.
+//
+// Returns nothing.
+func (wp *workerPool) discardPending() {
+	wp.pauseMutex.Lock()
+	for _, e := range wp.held {
+		wp.DecMetric(e.job, "workerpool_job_held")
+		wp.discard(e)
+	}
+	wp.held = nil
+	wp.pauseMutex.Unlock()
+
+	wp.DelayedMutext.Lock()
+	defer wp.DelayedMutext.Unlock()
+
+	for schedule, envelopes := range wp.Delayed {
+		for _, e := range envelopes {
+			wp.DecMetric(e.job, "workerpool_job_scheduled")
+			wp.endPhase(e)
+			wp.Logger.Debug("job discarded", jobFields(e, "schedule", schedule)...)
+			wp.Hooks.discard(e.job)
+			wp.discardStatus(e)
+		}
+
+		delete(wp.Delayed, schedule)
+	}
+}
+
+// IncMetric increments any given job metric, actually it's a wrapper func to
+// label the metrics with the job labels everytime that we want to report a
 // value.
 //
+// - job: The job which labels the metrics.
 // - metrics: THe metrics to increment.
 //
 // Returns nothing.
-func (wp *workerPool) DecMetric(metrics ...string) {
-	if wp.Metrics != nil {
-		wp.Metrics.Dec(metrics...)
+func (wp *workerPool) IncMetric(job Runnable, metrics ...string) {
+	wp.Metrics.IncWith(wp.jobLabels(job), metrics...)
+}
+
+// ObserveMetric adds an observation to any given job histogram metric,
+// actually it's a wrapper func to label the metric with the job labels
+// everytime that we want to report a value.
+//
+// - job: The job which labels the metric.
+// - metric: The metric to observe.
+// - value: The observed value.
+//
+// Returns nothing.
+func (wp *workerPool) ObserveMetric(job Runnable, metric string, value float64) {
+	wp.Metrics.ObserveWith(wp.jobLabels(job), metric, value)
+}
+
+// DecMetric decrements any given job metric, actually it's a wrapper func to
+// label the metrics with the job labels everytime that we want to report a
+// value.
+//
+// - job: The job which labels the metrics.
+// - metrics: THe metrics to decrement.
+//
+// Returns nothing.
+func (wp *workerPool) DecMetric(job Runnable, metrics ...string) {
+	wp.Metrics.DecWith(wp.jobLabels(job), metrics...)
+}
+
+// jobLabels returns the metric labels for the given job, the job type is only
+// used if it's on the JobTypes allowlist.
+//
+// - job: The job to get the labels for.
+//
+// Returns the job metric labels.
+func (wp *workerPool) jobLabels(job Runnable) map[string]string {
+	t := jobType(job)
+	if len(wp.JobTypes) > 0 && !wp.JobTypes[t] {
+		t = "other"
+	}
+
+	return map[string]string{
+		"pool":     wp.Name,
+		"job_type": t,
 	}
 }
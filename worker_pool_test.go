@@ -2,18 +2,21 @@ package thrall
 
 import (
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/jcleira/thrall/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
 type testJob struct {
-	Executed bool
+	Executed atomic.Bool
 }
 
 func (tj *testJob) Run() error {
-	tj.Executed = true
+	tj.Executed.Store(true)
 	return nil
 }
 
@@ -25,16 +28,26 @@ func (sj *scheduleableJob) Schedule() time.Time {
 	return time.Now().Add(1 * time.Hour)
 }
 
+type blockingJob struct {
+	testJob
+	release chan struct{}
+}
+
+func (bj *blockingJob) Run() error {
+	<-bj.release
+	return bj.testJob.Run()
+}
+
 type repeatableJob struct {
 	testJob
-	Repeated int
+	Repeated atomic.Int32
 }
 
 func (r *repeatableJob) Repeat() bool {
-	if r.Repeated == 2 {
+	if r.Repeated.Load() == 2 {
 		return false
 	}
-	r.Repeated += 1
+	r.Repeated.Add(1)
 
 	return true
 }
@@ -71,8 +84,109 @@ func TestSchedule(t *testing.T) {
 		queue <- &scheduleableJob{}
 		time.Sleep(10 * time.Millisecond)
 
-		assert.Len(wp.Delayed, 1)
+		assert.Equal(1, Stats().Scheduled)
+
+		close <- true
+	})
+
+	t.Run("when enqueueScheduled succeed releasing the scheduler while dispatching", func(t *testing.T) {
+		queue, _, close := Init(1)
+
+		release := make(chan struct{})
+		queue <- &blockingJob{release: release}
+		queue <- &nowJob{}
+		time.Sleep(1100 * time.Millisecond)
+
+		stats := make(chan PoolStats)
+		go func() { stats <- Stats() }()
+
+		select {
+		case s := <-stats:
+			assert.Equal(0, s.Scheduled)
+		case <-time.After(100 * time.Millisecond):
+			assert.Fail("the scheduler is locked while dispatching")
+		}
+
+		release <- struct{}{}
+		close <- true
+	})
+}
+
+func TestWithMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when WithMetrics succeed registering the workerPool metrics", func(t *testing.T) {
+		queue, _, close := Init(1, WithMaxLimiter(0), WithMetrics(0.1, 1))
+		registry := wp.Metrics.(*metrics.Registry)
+
+		queue <- &testJob{}
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Contains(registry.HistogramVecs, "job_wait_seconds")
+		assert.Contains(registry.HistogramVecs, "job_run_seconds")
+		assert.Contains(registry.HistogramVecs, "job_schedule_lateness_seconds")
+		assert.Contains(registry.Funcs, "limiter_in_use")
+		assert.Contains(registry.Funcs, "limiter_capacity")
+		assert.Contains(registry.Funcs, "limiter_denied")
+		assert.NotZero(wp.Limiter.Stats().Denied)
 
 		close <- true
 	})
 }
+
+func TestWithMetricsRegistry(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when WithMetricsRegistry succeed registering on the given registry", func(t *testing.T) {
+		registerer := prometheus.NewRegistry()
+
+		for i := 0; i < 2; i++ {
+			registry := metrics.NewRegistry(
+				metrics.WithRegisterer(registerer),
+				metrics.WithNamespace("foo"),
+			)
+
+			queue, _, close := Init(1, WithMetricsRegistry(registry))
+
+			queue <- &testJob{}
+			time.Sleep(10 * time.Millisecond)
+
+			close <- true
+		}
+
+		families, err := registerer.Gather()
+		assert.Nil(err)
+
+		names := make(map[string]float64)
+		for _, family := range families {
+			for _, metric := range family.GetMetric() {
+				names[family.GetName()] += metric.GetCounter().GetValue()
+			}
+		}
+
+		assert.Contains(names, "foo_workerpool_job_received")
+		assert.Contains(names, "foo_limiter_in_use")
+		assert.Equal(2.0, names["foo_workerpool_job_received"])
+	})
+}
+
+func TestJobLabels(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when jobLabels succeed labeling by the job type", func(t *testing.T) {
+		pool := &workerPool{Name: "foo"}
+
+		assert.Equal(map[string]string{"pool": "foo", "job_type": "testJob"},
+			pool.jobLabels(&testJob{}))
+	})
+
+	t.Run("when jobLabels succeed guarding the job types allowlist", func(t *testing.T) {
+		pool := &workerPool{Name: "foo"}
+		WithJobTypes("named")(pool)
+
+		assert.Equal(map[string]string{"pool": "foo", "job_type": "named"},
+			pool.jobLabels(&namedJob{}))
+		assert.Equal(map[string]string{"pool": "foo", "job_type": "other"},
+			pool.jobLabels(&testJob{}))
+	})
+}
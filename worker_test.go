@@ -18,7 +18,7 @@ func TestWorkerEnqueue(t *testing.T) {
 			queue <- &job
 			time.Sleep(10 * time.Millisecond)
 
-			assert.True(job.Executed)
+			assert.True(job.Executed.Load())
 
 			close <- true
 		})
@@ -32,7 +32,7 @@ func TestWorkerEnqueue(t *testing.T) {
 			queue <- &job
 			time.Sleep(10 * time.Millisecond)
 
-			assert.True(job.Executed)
+			assert.True(job.Executed.Load())
 
 			close <- true
 		})
@@ -44,7 +44,7 @@ func TestWorkerEnqueue(t *testing.T) {
 			queue <- &job
 			time.Sleep(10 * time.Millisecond)
 
-			assert.False(job.Executed)
+			assert.False(job.Executed.Load())
 
 			close <- true
 		})
@@ -60,8 +60,8 @@ func TestWorkerRun(t *testing.T) {
 		queue <- &job
 		time.Sleep(10 * time.Millisecond)
 
-		assert.True(job.Executed)
-		assert.Equal(2, job.Repeated)
+		assert.True(job.Executed.Load())
+		assert.Equal(int32(2), job.Repeated.Load())
 
 		close <- true
 	})
@@ -0,0 +1,345 @@
+package thrall
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FailurePolicy defines how a workflow handles a failed node.
+type FailurePolicy int
+
+const (
+	// FailFast stops the workflow on the first failed node, the running nodes
+	// are canceled and the pending ones skipped.
+	FailFast FailurePolicy = iota
+
+	// ContinueOnFailure skips only the failed node's dependents, the nodes
+	// that don't depend on it keep running.
+	ContinueOnFailure
+)
+
+// Workflow is a DAG of jobs, it's nodes are run through thrall once all their
+// dependencies succeed. Build it with NewWorkflow, Add and Edge, then Run it.
+type Workflow struct {
+	// Name is the workflow name, It's used to label it's logs.
+	Name string
+
+	// FailurePolicy is how the workflow handles a failed node, it fails fast
+	// by default.
+	FailurePolicy FailurePolicy
+
+	nodes map[string]*workflowNode
+	order []string
+	err   error
+}
+
+// workflowNode is a workflow's job and the nodes it depends on.
+type workflowNode struct {
+	name      string
+	job       Runnable
+	dependsOn []string
+}
+
+// NodeResult is a workflow node's final state.
+type NodeResult struct {
+	Name string `json:"name"`
+
+	// JobID is the node's job ID, empty if the node has been skipped.
+	JobID string   `json:"job_id,omitempty"`
+	State JobState `json:"state"`
+	Error string   `json:"error,omitempty"`
+}
+
+// WorkflowResult is a workflow run's final state.
+type WorkflowResult struct {
+	Name     string                `json:"name"`
+	Nodes    map[string]NodeResult `json:"nodes"`
+	Started  time.Time             `json:"started"`
+	Finished time.Time             `json:"finished"`
+}
+
+// Succeeded returns true if every node of the workflow succeeded.
+//
+// Returns true if the workflow succeeded.
+func (r *WorkflowResult) Succeeded() bool {
+	for _, node := range r.Nodes {
+		if node.State != StateSucceeded {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewWorkflow creates an empty workflow.
+//
+// - name: The workflow name.
+//
+// Returns the empty workflow.
+func NewWorkflow(name string) *Workflow {
+	return &Workflow{
+		Name:  name,
+		nodes: make(map[string]*workflowNode),
+	}
+}
+
+// Add adds a node to the workflow, the builder errors are returned by
+// Validate and Run.
+//
+// - name: The node name, unique within the workflow.
+// - job: The node's job.
+// - dependsOn: The names of the nodes that must succeed before this one runs.
+//
+// Returns the workflow, to chain the builder calls.
+func (w *Workflow) Add(name string, job Runnable, dependsOn ...string) *Workflow {
+	if _, exists := w.nodes[name]; exists {
+		w.fail(fmt.Errorf("workflow node '%s' already added", name))
+		return w
+	}
+
+	if job == nil {
+		w.fail(fmt.Errorf("workflow node '%s' has no job", name))
+		return w
+	}
+
+	w.nodes[name] = &workflowNode{name: name, job: job, dependsOn: dependsOn}
+	w.order = append(w.order, name)
+
+	return w
+}
+
+// Edge adds a dependency between two nodes, the to node runs after the from
+// node succeeds.
+//
+// - from: The dependency's node name.
+// - to: The dependent's node name.
+//
+// Returns the workflow, to chain the builder calls.
+func (w *Workflow) Edge(from, to string) *Workflow {
+	node, exists := w.nodes[to]
+	if !exists {
+		w.fail(fmt.Errorf("workflow node '%s' not found", to))
+		return w
+	}
+
+	node.dependsOn = append(node.dependsOn, from)
+
+	return w
+}
+
+// Validate checks that the workflow's nodes dependencies exist and that the
+// graph is acyclic.
+//
+// Returns the first builder error, or an error naming the nodes on a cycle.
+func (w *Workflow) Validate() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	remaining := make(map[string]int, len(w.nodes))
+	dependents := make(map[string][]string, len(w.nodes))
+
+	for _, name := range w.order {
+		for _, dependency := range w.nodes[name].dependsOn {
+			if _, exists := w.nodes[dependency]; !exists {
+				return fmt.Errorf("workflow node '%s' depends on unknown node '%s'",
+					name, dependency)
+			}
+
+			remaining[name]++
+			dependents[dependency] = append(dependents[dependency], name)
+		}
+	}
+
+	var ready []string
+	for _, name := range w.order {
+		if remaining[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	visited := 0
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		visited++
+
+		for _, dependent := range dependents[name] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if visited == len(w.nodes) {
+		return nil
+	}
+
+	var cycle []string
+	for _, name := range w.order {
+		if remaining[name] > 0 {
+			cycle = append(cycle, name)
+		}
+	}
+
+	return fmt.Errorf("workflow '%s' has a cycle between the nodes %v", w.Name, cycle)
+}
+
+// Run validates the workflow and runs it through thrall, dispatching every
+// node once all it's dependencies succeed, and blocks until every dispatched
+// node is finished. Canceling the context stops the workflow as a FailFast
+// failure does.
+//
+// - ctx: The workflow's context.
+//
+// Returns every node's final state, or an error if the workflow is not valid
+// or the context has been canceled.
+func (w *Workflow) Run(ctx context.Context) (*WorkflowResult, error) {
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+
+	return wp.runWorkflow(ctx, w)
+}
+
+// fail keeps the first builder error.
+//
+// - err: The builder error.
+//
+// Returns nothing.
+func (w *Workflow) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// runWorkflow runs a valid workflow.
+//
+// - ctx: The workflow's context.
+// - w: The workflow to run.
+//
+// Returns every node's final state, or the context's error if it has been
+// canceled.
+func (wp *workerPool) runWorkflow(ctx context.Context, w *Workflow) (*WorkflowResult, error) {
+	result := &WorkflowResult{
+		Name:    w.Name,
+		Nodes:   make(map[string]NodeResult, len(w.nodes)),
+		Started: time.Now(),
+	}
+
+	remaining := make(map[string]int, len(w.nodes))
+	dependents := make(map[string][]string, len(w.nodes))
+	for _, name := range w.order {
+		for _, dependency := range w.nodes[name].dependsOn {
+			remaining[name]++
+			dependents[dependency] = append(dependents[dependency], name)
+		}
+	}
+
+	// done receives every node's final state once, so it never blocks.
+	done := make(chan NodeResult, len(w.nodes))
+	running := make(map[string]string)
+
+	launch := func(name string) {
+		id, err := wp.submit(w.nodes[name].job)
+		if err != nil {
+			done <- NodeResult{Name: name, State: StateFailed, Error: err.Error()}
+			return
+		}
+
+		watching := wp.watch(id, func(status JobStatus) {
+			done <- NodeResult{Name: name, JobID: id, State: status.State, Error: status.Error}
+		})
+		if !watching {
+			done <- NodeResult{Name: name, JobID: id, State: StateFailed, Error: ErrJobNotFound.Error()}
+			return
+		}
+
+		running[name] = id
+	}
+
+	skip := func(name string) {
+		if _, finished := result.Nodes[name]; finished {
+			return
+		}
+
+		if _, started := running[name]; started {
+			return
+		}
+
+		result.Nodes[name] = NodeResult{Name: name, State: StateSkipped}
+	}
+
+	var skipDependents func(name string)
+	skipDependents = func(name string) {
+		for _, dependent := range dependents[name] {
+			skip(dependent)
+			skipDependents(dependent)
+		}
+	}
+
+	aborted := false
+	abort := func() {
+		aborted = true
+
+		for _, id := range running {
+			wp.cancel(id)
+		}
+
+		for _, name := range w.order {
+			skip(name)
+		}
+	}
+
+	wp.Logger.Info("workflow started", "pool", wp.Name, "workflow", w.Name,
+		"nodes", len(w.nodes))
+
+	for _, name := range w.order {
+		if remaining[name] == 0 {
+			launch(name)
+		}
+	}
+
+	var err error
+	ctxDone := ctx.Done()
+
+	for len(result.Nodes) < len(w.nodes) || len(running) > 0 {
+		select {
+		case <-ctxDone:
+			err, ctxDone = ctx.Err(), nil
+			abort()
+			continue
+		case node := <-done:
+			delete(running, node.Name)
+			result.Nodes[node.Name] = node
+
+			if node.State != StateSucceeded {
+				wp.Logger.Error("workflow node failed", "pool", wp.Name,
+					"workflow", w.Name, "node", node.Name, "state", node.State,
+					"error", node.Error)
+
+				if w.FailurePolicy == FailFast && !aborted {
+					abort()
+				}
+
+				skipDependents(node.Name)
+				continue
+			}
+
+			for _, dependent := range dependents[node.Name] {
+				remaining[dependent]--
+				if remaining[dependent] == 0 && !aborted {
+					launch(dependent)
+				}
+			}
+		}
+	}
+
+	result.Finished = time.Now()
+	wp.Logger.Info("workflow finished", "pool", wp.Name, "workflow", w.Name,
+		"succeeded", result.Succeeded(), "duration", result.Finished.Sub(result.Started))
+
+	return result, err
+}
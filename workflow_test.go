@@ -0,0 +1,134 @@
+package thrall
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stepJob struct {
+	Name string
+	Fail bool
+	runs chan string
+}
+
+func (sj *stepJob) Run() error {
+	sj.runs <- sj.Name
+	if sj.Fail {
+		return errors.New("step failed")
+	}
+
+	return nil
+}
+
+func TestWorkflow(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("when Workflow succeed running the nodes after their dependencies", func(t *testing.T) {
+		_, _, close := Init(2)
+		defer func() { close <- true }()
+
+		runs := make(chan string, 4)
+		step := func(name string) *stepJob { return &stepJob{Name: name, runs: runs} }
+
+		result, err := NewWorkflow("etl").
+			Add("a", step("a")).
+			Add("b", step("b"), "a").
+			Add("c", step("c")).
+			Add("d", step("d"), "b", "c").
+			Edge("a", "c").
+			Run(context.Background())
+
+		assert.Nil(err)
+		assert.True(result.Succeeded())
+		assert.Len(result.Nodes, 4)
+		assert.NotEmpty(result.Nodes["d"].JobID)
+
+		assert.Equal("a", <-runs)
+		<-runs
+		<-runs
+		assert.Equal("d", <-runs)
+	})
+
+	t.Run("when Workflow succeed skipping a failed node's dependents", func(t *testing.T) {
+		_, _, close := Init(2, WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		runs := make(chan string, 4)
+
+		w := NewWorkflow("etl").
+			Add("a", &stepJob{Name: "a", Fail: true, runs: runs}).
+			Add("b", &stepJob{Name: "b", runs: runs}, "a").
+			Add("c", &stepJob{Name: "c", runs: runs}, "b").
+			Add("d", &stepJob{Name: "d", runs: runs})
+		w.FailurePolicy = ContinueOnFailure
+
+		result, err := w.Run(context.Background())
+		assert.Nil(err)
+		assert.False(result.Succeeded())
+
+		assert.Equal(StateFailed, result.Nodes["a"].State)
+		assert.Equal("step failed", result.Nodes["a"].Error)
+		assert.Equal(StateSkipped, result.Nodes["b"].State)
+		assert.Equal(StateSkipped, result.Nodes["c"].State)
+		assert.Empty(result.Nodes["c"].JobID)
+		assert.Equal(StateSucceeded, result.Nodes["d"].State)
+	})
+
+	t.Run("when Workflow succeed failing fast", func(t *testing.T) {
+		_, _, close := Init(2, WithErrorsBuffer(2))
+		defer func() { close <- true }()
+
+		runs := make(chan string, 4)
+
+		result, err := NewWorkflow("etl").
+			Add("slow", &waitingJob{}).
+			Add("a", &stepJob{Name: "a", Fail: true, runs: runs}).
+			Add("b", &stepJob{Name: "b", runs: runs}, "slow").
+			Run(context.Background())
+
+		assert.Nil(err)
+		assert.Equal(StateFailed, result.Nodes["a"].State)
+		assert.Equal(StateCanceled, result.Nodes["slow"].State)
+		assert.Equal(StateSkipped, result.Nodes["b"].State)
+	})
+
+	t.Run("when Workflow succeed stopping on the context cancelation", func(t *testing.T) {
+		_, _, close := Init(1, WithErrorsBuffer(1))
+		defer func() { close <- true }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		result, err := NewWorkflow("etl").
+			Add("slow", &waitingJob{}).
+			Add("next", &testJob{}, "slow").
+			Run(ctx)
+
+		assert.Equal(context.DeadlineExceeded, err)
+		assert.Equal(StateCanceled, result.Nodes["slow"].State)
+		assert.Equal(StateSkipped, result.Nodes["next"].State)
+	})
+
+	t.Run("when Workflow fails due an invalid graph", func(t *testing.T) {
+		err := NewWorkflow("etl").
+			Add("a", &testJob{}, "c").
+			Add("b", &testJob{}, "a").
+			Add("c", &testJob{}, "b").
+			Add("d", &testJob{}).
+			Validate()
+		assert.EqualError(err, "workflow 'etl' has a cycle between the nodes [a b c]")
+
+		err = NewWorkflow("etl").Add("a", &testJob{}, "foo").Validate()
+		assert.EqualError(err, "workflow node 'a' depends on unknown node 'foo'")
+
+		err = NewWorkflow("etl").Add("a", &testJob{}).Add("a", &testJob{}).Validate()
+		assert.EqualError(err, "workflow node 'a' already added")
+
+		err = NewWorkflow("etl").Edge("a", "b").Validate()
+		assert.EqualError(err, "workflow node 'b' not found")
+	})
+}